@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type CellEventHandler func(row, col int)
+
+type CellEvent struct {
+	handlers []CellEventHandler
+}
+
+func (e *CellEvent) Attach(handler CellEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *CellEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type CellEventPublisher struct {
+	event CellEvent
+}
+
+func (p *CellEventPublisher) Event() *CellEvent {
+	return &p.event
+}
+
+func (p *CellEventPublisher) Publish(row, col int) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(row, col)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type ColumnFilterClickEventHandler func(col int, bounds Rectangle)
+
+type ColumnFilterClickEvent struct {
+	handlers []ColumnFilterClickEventHandler
+}
+
+func (e *ColumnFilterClickEvent) Attach(handler ColumnFilterClickEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ColumnFilterClickEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ColumnFilterClickEventPublisher struct {
+	event ColumnFilterClickEvent
+}
+
+func (p *ColumnFilterClickEventPublisher) Event() *ColumnFilterClickEvent {
+	return &p.event
+}
+
+func (p *ColumnFilterClickEventPublisher) Publish(col int, bounds Rectangle) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(col, bounds)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type ColumnHeaderClickEventHandler func(col int, button MouseButton, modifiers Modifiers)
+
+type ColumnHeaderClickEvent struct {
+	handlers []ColumnHeaderClickEventHandler
+}
+
+func (e *ColumnHeaderClickEvent) Attach(handler ColumnHeaderClickEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ColumnHeaderClickEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ColumnHeaderClickEventPublisher struct {
+	event ColumnHeaderClickEvent
+}
+
+func (p *ColumnHeaderClickEventPublisher) Event() *ColumnHeaderClickEvent {
+	return &p.event
+}
+
+func (p *ColumnHeaderClickEventPublisher) Publish(col int, button MouseButton, modifiers Modifiers) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(col, button, modifiers)
+		}
+	}
+}
@@ -53,15 +53,23 @@ type TableView struct {
 	Columns                    []TableViewColumn
 	ColumnsOrderable           Property
 	ColumnsSizable             Property
+	CompactMode                bool
+	Gridlines                  bool
 	HeaderHidden               bool
 	ItemStateChangedEventDelay int
 	LastColumnStretched        bool
 	Model                      interface{}
 	MultiSelection             bool
 	NotSortableByHeaderClick   bool
+	OnCellEdited               walk.CellEventHandler
 	OnCurrentIndexChanged      walk.EventHandler
 	OnItemActivated            walk.EventHandler
+	OnItemContextMenu          walk.ItemContextMenuEventHandler
 	OnSelectedIndexesChanged   walk.EventHandler
+	RowHeight                  int
+	RowNumbersVisible          bool
+	SelectedRowBGColor         walk.Color
+	SelectedRowTextColor       walk.Color
 	StyleCell                  func(style *walk.CellStyle)
 }
 
@@ -75,8 +83,10 @@ func (tvs *tvStyler) StyleCell(style *walk.CellStyle) {
 		tvs.dflt.StyleCell(style)
 	}
 
-	if styleCell := tvs.colStyleCellFuncs[style.Col()]; styleCell != nil {
-		styleCell(style)
+	if col := style.Col(); col > -1 {
+		if styleCell := tvs.colStyleCellFuncs[col]; styleCell != nil {
+			styleCell(style)
+		}
 	}
 }
 
@@ -151,7 +161,15 @@ func (tv TableView) Create(builder *Builder) error {
 		if tv.AlternatingRowBGColor != 0 {
 			w.SetAlternatingRowBGColor(tv.AlternatingRowBGColor)
 		}
+		if tv.SelectedRowBGColor != 0 {
+			w.SetSelectedRowBGColor(tv.SelectedRowBGColor)
+		}
+		if tv.SelectedRowTextColor != 0 {
+			w.SetSelectedRowTextColor(tv.SelectedRowTextColor)
+		}
 		w.SetCheckBoxes(tv.CheckBoxes)
+		w.SetCompactMode(tv.CompactMode)
+		w.SetGridlines(tv.Gridlines)
 		w.SetItemStateChangedEventDelay(tv.ItemStateChangedEventDelay)
 		if err := w.SetLastColumnStretched(tv.LastColumnStretched); err != nil {
 			return err
@@ -162,6 +180,16 @@ func (tv TableView) Create(builder *Builder) error {
 		if err := w.SetHeaderHidden(tv.HeaderHidden); err != nil {
 			return err
 		}
+		if tv.RowHeight > 0 {
+			if err := w.SetRowHeight(tv.RowHeight); err != nil {
+				return err
+			}
+		}
+		if tv.RowNumbersVisible {
+			if err := w.SetRowNumbersVisible(true); err != nil {
+				return err
+			}
+		}
 
 		if tv.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(tv.OnCurrentIndexChanged)
@@ -172,6 +200,12 @@ func (tv TableView) Create(builder *Builder) error {
 		if tv.OnItemActivated != nil {
 			w.ItemActivated().Attach(tv.OnItemActivated)
 		}
+		if tv.OnCellEdited != nil {
+			w.CellEdited().Attach(tv.OnCellEdited)
+		}
+		if tv.OnItemContextMenu != nil {
+			w.ItemContextMenu().Attach(tv.OnItemContextMenu)
+		}
 
 		if tv.AssignTo != nil {
 			*tv.AssignTo = w
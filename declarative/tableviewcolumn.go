@@ -19,16 +19,21 @@ const (
 )
 
 type TableViewColumn struct {
-	Name       string
-	DataMember string
-	Format     string
-	Title      string
-	Alignment  Alignment1D
-	Precision  int
-	Width      int
-	Hidden     bool
-	Frozen     bool
-	StyleCell  func(style *walk.CellStyle)
+	Name          string
+	DataMember    string
+	Format        string
+	Title         string
+	Alignment     Alignment1D
+	Precision     int
+	Width         int
+	Hidden        bool
+	Frozen        bool
+	Editable      bool
+	Editor        walk.CellEditor
+	Kind          walk.ColumnKind
+	HeaderImage   interface{}
+	HeaderToolTip string
+	StyleCell     func(style *walk.CellStyle)
 }
 
 func (tvc TableViewColumn) Create(tv *walk.TableView) error {
@@ -59,6 +64,28 @@ func (tvc TableViewColumn) Create(tv *walk.TableView) error {
 	if err := w.SetWidth(tvc.Width); err != nil {
 		return err
 	}
+	if tvc.HeaderImage != nil {
+		w.SetHeaderImage(tvc.HeaderImage)
+	}
+	w.SetHeaderToolTip(tvc.HeaderToolTip)
+
+	if err := tv.Columns().Add(w); err != nil {
+		return err
+	}
+
+	if err := tv.SetCellEditable(tv.Columns().Len()-1, tvc.Editable); err != nil {
+		return err
+	}
+
+	if tvc.Editor != nil {
+		if err := tv.SetColumnEditor(tv.Columns().Len()-1, tvc.Editor); err != nil {
+			return err
+		}
+	}
+
+	if tvc.Kind != walk.ColumnKindText {
+		return tv.SetColumnKind(tv.Columns().Len()-1, tvc.Kind)
+	}
 
-	return tv.Columns().Add(w)
+	return nil
 }
@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type ItemContextMenuEventHandler func(row, col int, screenPos Point)
+
+type ItemContextMenuEvent struct {
+	handlers []ItemContextMenuEventHandler
+}
+
+func (e *ItemContextMenuEvent) Attach(handler ItemContextMenuEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ItemContextMenuEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ItemContextMenuEventPublisher struct {
+	event ItemContextMenuEvent
+}
+
+func (p *ItemContextMenuEventPublisher) Event() *ItemContextMenuEvent {
+	return &p.event
+}
+
+func (p *ItemContextMenuEventPublisher) Publish(row, col int, screenPos Point) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(row, col, screenPos)
+		}
+	}
+}
@@ -114,11 +114,23 @@ type TableModel interface {
 	RowsRemoved() *IntRangeEvent
 }
 
+// RowsChanger is the interface that a TableModel can implement to report
+// changes of a contiguous range of rows at once.
+//
+// Unlike RowsReset, this keeps the current index, selection and scroll
+// position of a TableView.
+type RowsChanger interface {
+	// RowsChanged returns the event that the model should publish when a
+	// contiguous range of rows was changed.
+	RowsChanged() *IntRangeEvent
+}
+
 // TableModelBase implements the RowsReset and RowChanged methods of the
 // TableModel interface.
 type TableModelBase struct {
 	rowsResetPublisher    EventPublisher
 	rowChangedPublisher   IntEventPublisher
+	rowsChangedPublisher  IntRangeEventPublisher
 	rowsInsertedPublisher IntRangeEventPublisher
 	rowsRemovedPublisher  IntRangeEventPublisher
 }
@@ -131,6 +143,10 @@ func (tmb *TableModelBase) RowChanged() *IntEvent {
 	return tmb.rowChangedPublisher.Event()
 }
 
+func (tmb *TableModelBase) RowsChanged() *IntRangeEvent {
+	return tmb.rowsChangedPublisher.Event()
+}
+
 func (tmb *TableModelBase) RowsInserted() *IntRangeEvent {
 	return tmb.rowsInsertedPublisher.Event()
 }
@@ -147,6 +163,10 @@ func (tmb *TableModelBase) PublishRowChanged(row int) {
 	tmb.rowChangedPublisher.Publish(row)
 }
 
+func (tmb *TableModelBase) PublishRowsChanged(from, to int) {
+	tmb.rowsChangedPublisher.Publish(from, to)
+}
+
 func (tmb *TableModelBase) PublishRowsInserted(from, to int) {
 	tmb.rowsInsertedPublisher.Publish(from, to)
 }
@@ -251,10 +271,125 @@ type ImageProvider interface {
 	Image(index int) interface{}
 }
 
+// CellImageProvider is the interface that a model can implement to display
+// images in any column of a widget like TableView, instead of only the first.
+type CellImageProvider interface {
+	// ImageForCell returns the image to display in the cell at row and col,
+	// or nil for no image.
+	//
+	// Supported types are the same as for ImageProvider.Image.
+	ImageForCell(row, col int) interface{}
+}
+
+// RowMover is the interface that a model must implement to support reordering
+// rows in a widget like TableView.
+type RowMover interface {
+	// MoveRow moves the row at index from, so it ends up at index to.
+	//
+	// MoveRow must publish the appropriate events, e.g. RowsReset.
+	MoveRow(from, to int) error
+}
+
+// ItemIDProvider is the interface that a model must implement to identify its
+// items across resets, e.g. to let a TableView keep its selection.
+type ItemIDProvider interface {
+	// ItemID returns a comparable value, that uniquely identifies the item at
+	// index row.
+	ItemID(row int) interface{}
+}
+
+// UniformRowHeighter is the interface that a model must implement to request
+// taller rows in a widget like TableView, e.g. for wrapped text.
+//
+// The list views of a TableView only support rows of the same height, so the
+// height applies to all rows. TableView queries it again whenever the rows of
+// the model change.
+type UniformRowHeighter interface {
+	// UniformRowHeight returns the minimum height of all rows in 1/96 inch,
+	// or 0 for the default height.
+	UniformRowHeight() int
+}
+
+// CellToolTipProvider is the interface that a model must implement to provide
+// custom tool tips for the cells of a widget like TableView.
+type CellToolTipProvider interface {
+	// CellToolTip returns the tool tip text for the cell at row and col, or ""
+	// to display the default tool tip, which shows truncated text in full.
+	CellToolTip(row, col int) string
+}
+
+// SummaryRowProvider is the interface that must be implemented to provide a
+// TableView with the values of a summary row, e.g. totals, which is displayed
+// below the data rows and does not scroll.
+type SummaryRowProvider interface {
+	// SummaryValue returns the value to display in the summary row for the
+	// column at index col. It is formatted like the cell values of the column.
+	SummaryValue(col int) interface{}
+}
+
+// PinnedRowProvider is the interface that must be implemented to pin rows of
+// a TableView, e.g. favorites, so they are displayed above the other rows and
+// do not scroll vertically.
+type PinnedRowProvider interface {
+	// PinnedRows returns the model indexes of the pinned rows, in the order
+	// in which they are displayed.
+	PinnedRows() []int
+}
+
+// RowSelectabilityProvider is the interface that a model can implement, if
+// some of its rows, e.g. separators, must not be selected in a TableView.
+type RowSelectabilityProvider interface {
+	// Selectable returns if the row at index row can be selected.
+	Selectable(row int) bool
+}
+
+// TextRun is a part of the text of a cell, that is displayed in its own style.
+type TextRun struct {
+	Text string
+
+	// TextColor is the color of the text, or 0 for the text color of the cell.
+	TextColor Color
+
+	// BackgroundColor is the color behind the text, e.g. to highlight it, or
+	// 0 for the background color of the cell.
+	BackgroundColor Color
+
+	// Bold specifies if the text is displayed in bold.
+	Bold bool
+}
+
+// RichTextProvider is the interface that a model can implement, to display
+// cells of a TableView with text in multiple styles, e.g. to highlight search
+// matches.
+type RichTextProvider interface {
+	// RichText returns the text of the cell at row and col as styled runs,
+	// or nil to display the value of the cell as usual. The runs are
+	// displayed in a single line.
+	RichText(row, col int) []TextRun
+}
+
+// AsyncValuer is the interface that a TableModel can implement, if obtaining
+// its values takes long, e.g. because they come from the network.
+//
+// A TableView then displays a placeholder for values that are not ready yet,
+// instead of blocking while it draws.
+type AsyncValuer interface {
+	// ValueAsync returns the value of the specified cell and true, if it is
+	// ready. Otherwise it returns false and should start obtaining the value
+	// in the background, unless it already does. Once the value is ready, the
+	// model should call TableView.AsyncValueReady.
+	ValueAsync(row, col int) (value interface{}, ready bool)
+}
+
 // CellStyler is the interface that must be implemented to provide a tabular
 // widget like TableView with cell display style information.
 type CellStyler interface {
 	// StyleCell is called for each cell to pick up cell style information.
+	//
+	// Before the cells of a row are styled, StyleCell is called once for the
+	// whole row, with style.Col() returning -1. A BackgroundColor set in this
+	// call takes precedence over the alternating row background color and is
+	// used as the initial BackgroundColor for the cells of the row.
 	StyleCell(style *CellStyle)
 }
 
@@ -309,6 +444,28 @@ type ItemChecker interface {
 	SetChecked(index int, checked bool) error
 }
 
+// TriStateItemChecker is the interface that a model must implement to support
+// check boxes with an indeterminate state in a widget like TableView.
+//
+// Without visual styles, the indeterminate state is displayed like the checked
+// state.
+type TriStateItemChecker interface {
+	ItemChecker
+
+	// CheckState returns the check state of the specified item.
+	CheckState(index int) CheckState
+
+	// SetCheckState sets the check state of the specified item.
+	SetCheckState(index int, state CheckState) error
+}
+
+// CellSetter is the interface that a model must implement to support editing
+// cells in a widget like TableView.
+type CellSetter interface {
+	// SetValue sets the value of the specified cell.
+	SetValue(row, col int, value interface{}) error
+}
+
 // SortOrder specifies the order by which items are sorted.
 type SortOrder int
 
@@ -343,6 +500,30 @@ type Sorter interface {
 	SortOrder() SortOrder
 }
 
+// MultiSorter is the interface that a model must implement to support sorting
+// by multiple columns with a widget like TableView.
+//
+// A TableView sorts a MultiSorter by multiple columns, if the user holds down
+// the control key while clicking column headers. Calling Sort must be
+// equivalent to calling SortBy with a single column.
+type MultiSorter interface {
+	Sorter
+
+	// SortBy sorts by columns, in order of decreasing priority, using the
+	// respective orders.
+	//
+	// SortBy must publish the event returned from SortChanged() after
+	// sorting.
+	SortBy(columns []int, orders []SortOrder) error
+
+	// SortedColumns returns the indexes of the currently sorted columns, in
+	// order of decreasing priority.
+	SortedColumns() []int
+
+	// SortOrders returns the sort orders of the currently sorted columns.
+	SortOrders() []SortOrder
+}
+
 // SorterBase implements the Sorter interface.
 //
 // You still need to provide your own implementation of at least the Sort method
@@ -116,6 +116,12 @@ func newReflectTableModel(dataSource interface{}) (TableModel, error) {
 			m.PublishRowChanged(index)
 		})
 
+		if rc, ok := dataSource.(RowsChanger); ok {
+			rc.RowsChanged().Attach(func(from, to int) {
+				m.PublishRowsChanged(from, to)
+			})
+		}
+
 		rtm.RowsReset().Attach(func() {
 			m.items = rtm.Items()
 			m.value = reflect.ValueOf(m.items)
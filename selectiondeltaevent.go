@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type SelectionDeltaEventHandler func(selected, deselected []int)
+
+type SelectionDeltaEvent struct {
+	handlers []SelectionDeltaEventHandler
+}
+
+func (e *SelectionDeltaEvent) Attach(handler SelectionDeltaEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *SelectionDeltaEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type SelectionDeltaEventPublisher struct {
+	event SelectionDeltaEvent
+}
+
+func (p *SelectionDeltaEventPublisher) Event() *SelectionDeltaEvent {
+	return &p.event
+}
+
+func (p *SelectionDeltaEventPublisher) Publish(selected, deselected []int) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(selected, deselected)
+		}
+	}
+}
@@ -7,10 +7,16 @@
 package walk
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"reflect"
+	"sort"
+	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"unsafe"
@@ -30,11 +36,50 @@ var (
 	checkmark                   = string([]byte{0xE2, 0x9C, 0x94})
 	tableViewFrozenLVWndProcPtr = syscall.NewCallback(tableViewFrozenLVWndProc)
 	tableViewNormalLVWndProcPtr = syscall.NewCallback(tableViewNormalLVWndProc)
+	tableViewHeaderWndProcPtr   = syscall.NewCallback(tableViewHeaderWndProc)
 )
 
+const tableViewCellPadding = 12
+
+// tableViewAsyncPlaceholder is displayed for values of an AsyncValuer, that
+// are not ready yet.
+const tableViewAsyncPlaceholder = "\u2026"
+
+// Not defined in package win.
+const (
+	lvmGetImageList = win.LVM_FIRST + 2
+	hdsCheckBoxes   = 0x0400
+)
+
+// nmHeader is NMHEADER, which is not defined in package win.
+type nmHeader struct {
+	Hdr     win.NMHDR
+	IItem   int32
+	IButton int32
+	Pitem   *win.HDITEM
+}
+
+// nmLVGetInfoTip is NMLVGETINFOTIP, which is not defined in package win.
+type nmLVGetInfoTip struct {
+	Hdr        win.NMHDR
+	DwFlags    uint32
+	PszText    *uint16
+	CchTextMax int32
+	IItem      int32
+	ISubItem   int32
+	LParam     uintptr
+}
+
 const (
 	tableViewCurrentIndexChangedTimerId = 1 + iota
 	tableViewSelectedIndexesChangedTimerId
+	tableViewColumnsOrderChangedTimerId
+	tableViewSelectionVetoedTimerId
+	tableViewVisibleRangeChangedTimerId
+	tableViewItemHoveredTimerId
+	tableViewStyledRowHeightTimerId
+	tableViewCellEditCommitTimerId
+	tableViewItemFlashTimerId
 )
 
 // TableView is a model based widget for record centric, tabular data.
@@ -47,14 +92,32 @@ type TableView struct {
 	frozenLVOrigWndProcPtr             uintptr
 	hwndNormal                         win.HWND
 	normalLVOrigWndProcPtr             uintptr
+	frozenHeaderOrigWndProcPtr         uintptr
+	normalHeaderOrigWndProcPtr         uintptr
 	columns                            *TableViewColumnList
 	model                              TableModel
 	providedModel                      interface{}
 	itemChecker                        ItemChecker
+	triStateItemChecker                TriStateItemChecker
+	cellSetter                         CellSetter
+	uniformRowHeighter                 UniformRowHeighter
+	rowMover                           RowMover
+	rowsReorderable                    bool
+	rowDragHwnd                        win.HWND
+	rowDragIndex                       int
+	rowDropIndex                       int
+	marqueeSelection                   bool
+	marqueeHwnd                        win.HWND
+	marqueeAnchor                      win.POINT
+	marqueeRect                        win.RECT
+	marqueeBaseIndexes                 []int
+	headerToolTip                      *ToolTip
+	headerToolTipTools                 []win.TOOLINFO
 	imageProvider                      ImageProvider
 	styler                             CellStyler
 	style                              CellStyle
 	customDrawItemHot                  bool
+	customDrawItemSelected             bool
 	hIml                               win.HIMAGELIST
 	usingSysIml                        bool
 	imageUintptr2Index                 map[uintptr]int32
@@ -62,33 +125,142 @@ type TableView struct {
 	rowsResetHandlerHandle             int
 	rowChangedHandlerHandle            int
 	rowsInsertedHandlerHandle          int
+	rowsChangedHandlerHandle           int
+	itemFlashes                        map[int]*tableViewItemFlash
 	rowsRemovedHandlerHandle           int
 	sortChangedHandlerHandle           int
 	selectedIndexes                    []int
+	deltaBaseSelectedIndexes           []int
+	selectionDeltaChangedPublisher     SelectionDeltaEventPublisher
 	prevIndex                          int
 	currentIndex                       int
 	currentIndexChangedPublisher       EventPublisher
 	selectedIndexesChangedPublisher    EventPublisher
 	itemActivatedPublisher             EventPublisher
+	rowsReorderedPublisher             EventPublisher
+	cellEditedPublisher                CellEventPublisher
+	cellClickedPublisher               CellEventPublisher
+	itemContextMenuPublisher           ItemContextMenuEventPublisher
 	columnClickedPublisher             IntEventPublisher
+	columnHeaderClickedPublisher       ColumnHeaderClickEventPublisher
+	columnWidthChangedPublisher        IntEventPublisher
+	columnFilterClickedPublisher       ColumnFilterClickEventPublisher
+	visibleRangeChangedPublisher       VisibleRangeEventPublisher
+	itemHoveredPublisher               IntEventPublisher
+	sortChangedPublisher               EventPublisher
+	hoveredIndex                       int
+	cellToolTipRow                     int
+	cellToolTipCol                     int
+	pendingHoveredIndex                int
+	visibleTop                         int
+	visibleCount                       int
+	itemCheckedChangedPublisher        IntEventPublisher
 	columnsOrderableChangedPublisher   EventPublisher
+	columnsOrderChangedPublisher       EventPublisher
 	columnsSizableChangedPublisher     EventPublisher
 	publishNextSelClear                bool
 	inSetSelectedIndexes               bool
+	inSelectionRemap                   bool
 	lastColumnStretched                bool
 	inEraseBkgnd                       bool
 	persistent                         bool
 	itemStateChangedEventDelay         int
 	alternatingRowBGColor              Color
+	selectedRowBGColor                 Color
+	selectedRowTextColor               Color
+	itemBGColor                        Color
 	hasDarkAltBGColor                  bool
 	delayedCurrentIndexChangedCanceled bool
 	sortedColumnIndex                  int
 	sortOrder                          SortOrder
+	initialSortColumn                  int
+	initialSortOrder                   SortOrder
+	columnDefaultsSaved                bool
 	formActivatingHandle               int
 	scrolling                          bool
 	inSetCurrentIndex                  bool
 	inMouseEvent                       bool
 	hasFrozenColumn                    bool
+	lvSizesUpdateDeferred              bool
+	headerImagesUpdateDeferred         bool
+	frozenColumnCount                  int
+	rowFilter                          func(row int) bool
+	rowNumbersVisible                  bool
+	alwaysShowVerticalScrollBar        bool
+	selectionChanging                  func(oldIndex, newIndex int) bool
+	clampingColumnWidth                bool
+	approvedIndex                      int
+	deleteKeyHandler                   func(indexes []int)
+	columnDragHandler                  func(from, to int) bool
+	clipboardCopyEnabled               bool
+	selectAllShortcut                  bool
+	wrapNavigation                     bool
+	checkBoxesOnly                     bool
+	gridlines                          bool
+	sortFunc                           func(col int, order SortOrder) error
+	sortedBySortFunc                   bool
+	wheelScrollLines                   int
+	wheelDelta                         int
+	boolCheckedGlyph                   string
+	boolUncheckedGlyph                 string
+	emptyText                          string
+	busy                               bool
+	busyMessage                        string
+	busyHadFocus                       bool
+	headerFont                         *Font
+	populator                          Populator
+	wrapTextColor                      Color
+	wrapTextFont                       *Font
+	customDrawRichText                 []TextRun
+	focusRectangleVisible              bool
+	summaryRowProvider                 SummaryRowProvider
+	pinnedRowProvider                  PinnedRowProvider
+	pinnedRows                         []int
+	pinnedRowsLayoutHeight             int
+	columnGroups                       []ColumnGroup
+	itemIDProvider                     ItemIDProvider
+	cellToolTipProvider                CellToolTipProvider
+	asyncValuer                        AsyncValuer
+	rowSelectability                   RowSelectabilityProvider
+	richTextProvider                   RichTextProvider
+	cellImageProvider                  CellImageProvider
+	updateDepth                        int
+	batchedChanges                     bool
+	batchedReset                       bool
+	batchedRowChange                   bool
+	batchCurrentRow                    int
+	batchSelectedRows                  []int
+	batchCurrentID                     interface{}
+	batchSelectedIDs                   []interface{}
+	dispInfoCounter                    *uint64
+	font2RowHeight                     map[*Font]int
+	styledRowHeight                    int
+	resortOnUpdate                     bool
+	preserveSelectionOnReset           bool
+	currentItemID                      interface{}
+	selectedItemIDs                    []interface{}
+	populateAhead                      int
+	populatedFrom                      int
+	populatedTo                        int
+	filteredRows                       []int
+	hwndCellEdit                       win.HWND
+	cellEditOrigWndProcPtr             uintptr
+	cellEditRow                        int
+	cellEditCol                        int
+	rowHeight                          int
+	modelRowHeight                     int
+	compactMode                        bool
+	effectiveRowHeight                 int
+	hImlCheckState                     win.HIMAGELIST
+	hImlHeader                         win.HIMAGELIST
+	headerImlImages                    win.HIMAGELIST
+	headerImageUintptr2Index           map[uintptr]int32
+	hasFilterButtons                   bool
+	checkAnchorIndex                   int
+	rowChecked                         []bool
+	checkedCount                       int
+	headerCheckState                   CheckState
+	hImlRowHeight                      win.HIMAGELIST
 }
 
 // NewTableView creates and returns a *TableView as child of the specified
@@ -105,6 +277,20 @@ func NewTableViewWithStyle(parent Container, style uint32) (*TableView, error) {
 		imageUintptr2Index:    make(map[uintptr]int32),
 		filePath2IconIndex:    make(map[string]int32),
 		formActivatingHandle:  -1,
+		frozenColumnCount:     -1,
+		rowDropIndex:          -1,
+		populateAhead:         -1,
+		hoveredIndex:          -1,
+		cellToolTipRow:        -1,
+		cellToolTipCol:        -1,
+		pendingHoveredIndex:   -1,
+		checkAnchorIndex:      -1,
+		approvedIndex:         -1,
+		clipboardCopyEnabled:  true,
+		selectAllShortcut:     true,
+		wheelScrollLines:      3,
+		resortOnUpdate:        true,
+		boolCheckedGlyph:      checkmark,
 	}
 
 	tv.columns = newTableViewColumnList(tv)
@@ -169,6 +355,16 @@ func NewTableViewWithStyle(parent Container, style uint32) (*TableView, error) {
 		return nil, lastError("SetWindowLongPtr")
 	}
 
+	// The headers are subclassed to make room for pinned rows below them.
+	tv.frozenHeaderOrigWndProcPtr = win.SetWindowLongPtr(tv.frozenHeaderHwnd(), win.GWLP_WNDPROC, tableViewHeaderWndProcPtr)
+	if tv.frozenHeaderOrigWndProcPtr == 0 {
+		return nil, lastError("SetWindowLongPtr")
+	}
+	tv.normalHeaderOrigWndProcPtr = win.SetWindowLongPtr(tv.normalHeaderHwnd(), win.GWLP_WNDPROC, tableViewHeaderWndProcPtr)
+	if tv.normalHeaderOrigWndProcPtr == 0 {
+		return nil, lastError("SetWindowLongPtr")
+	}
+
 	tv.SetPersistent(true)
 
 	exStyle := win.SendMessage(tv.hwndFrozen, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)
@@ -221,13 +417,7 @@ func NewTableViewWithStyle(parent Container, style uint32) (*TableView, error) {
 
 	tv.MustRegisterProperty("CurrentItem", NewReadOnlyProperty(
 		func() interface{} {
-			if i := tv.CurrentIndex(); i > -1 {
-				if rm, ok := tv.providedModel.(reflectModel); ok {
-					return reflect.ValueOf(rm.Items()).Index(i).Interface()
-				}
-			}
-
-			return nil
+			return tv.itemAt(tv.CurrentIndex())
 		},
 		tv.CurrentIndexChanged()))
 
@@ -243,17 +433,155 @@ func NewTableViewWithStyle(parent Container, style uint32) (*TableView, error) {
 		},
 		tv.SelectedIndexesChanged()))
 
+	tv.MustRegisterProperty("SelectedItem", NewReadOnlyProperty(
+		func() interface{} {
+			if len(tv.selectedIndexes) != 1 {
+				return nil
+			}
+
+			return tv.itemAt(tv.selectedIndexes[0])
+		},
+		tv.SelectedIndexesChanged()))
+
+	tv.MustRegisterProperty("SelectedItems", NewReadOnlyProperty(
+		func() interface{} {
+			items := tv.reflectItems()
+			if items == nil {
+				return nil
+			}
+
+			itemsValue := reflect.ValueOf(items)
+			selected := reflect.MakeSlice(itemsValue.Type(), 0, len(tv.selectedIndexes))
+
+			for _, i := range tv.selectedIndexes {
+				if row := tv.ViewToModelIndex(i); row > -1 && row < itemsValue.Len() {
+					selected = reflect.Append(selected, itemsValue.Index(row))
+				}
+			}
+
+			return selected.Interface()
+		},
+		tv.SelectedIndexesChanged()))
+
 	succeeded = true
 
 	return tv, nil
 }
 
+// reflectItems returns the items of a slice or ReflectTableModel based model,
+// or nil.
+func (tv *TableView) reflectItems() interface{} {
+	if rm, ok := tv.providedModel.(reflectModel); ok {
+		return rm.Items()
+	} else if rtm, ok := tv.model.(*reflectTableModel); ok {
+		return rtm.items
+	}
+
+	return nil
+}
+
+// itemAt returns the item of a slice or ReflectTableModel based model, that is
+// displayed at index, or nil.
+func (tv *TableView) itemAt(index int) interface{} {
+	row := tv.ViewToModelIndex(index)
+	if row < 0 {
+		return nil
+	}
+
+	items := tv.reflectItems()
+	if items == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(items)
+	if row >= value.Len() {
+		return nil
+	}
+
+	return value.Index(row).Interface()
+}
+
+// SelectedItemsTyped stores the selected items of a slice or
+// ReflectTableModel based model in out, which must be a pointer to a slice of
+// the item type of the model, e.g. *[]*Foo for a []*Foo model.
+//
+// The items are stored in the order of their indexes.
+func (tv *TableView) SelectedItemsTyped(out interface{}) error {
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() != reflect.Ptr || outValue.IsNil() || outValue.Elem().Kind() != reflect.Slice {
+		return newError("out must be a non-nil pointer to a slice")
+	}
+
+	items := tv.reflectItems()
+	if items == nil {
+		return newError("model is not slice or ReflectTableModel based")
+	}
+
+	itemsValue := reflect.ValueOf(items)
+	sliceType := outValue.Elem().Type()
+
+	if itemType := itemsValue.Type().Elem(); !itemType.AssignableTo(sliceType.Elem()) {
+		return newError(fmt.Sprintf("items of type %s cannot be stored in %s", itemType, sliceType))
+	}
+
+	selected := reflect.MakeSlice(sliceType, 0, len(tv.selectedIndexes))
+
+	for _, i := range tv.selectedIndexes {
+		if row := tv.ViewToModelIndex(i); row > -1 && row < itemsValue.Len() {
+			selected = reflect.Append(selected, itemsValue.Index(row))
+		}
+	}
+
+	outValue.Elem().Set(selected)
+
+	return nil
+}
+
+// FrozenHWND returns the window handle of the list view, that displays the
+// frozen columns.
+//
+// It is meant for advanced interop that the *TableView does not support
+// itself. The *TableView relies on the state of its list views, so changing
+// it behind its back, e.g. its styles, columns or item count, can break it.
+func (tv *TableView) FrozenHWND() win.HWND {
+	return tv.hwndFrozen
+}
+
+// NormalHWND returns the window handle of the list view, that displays the
+// columns that are not frozen.
+//
+// The same caveats as for FrozenHWND apply.
+func (tv *TableView) NormalHWND() win.HWND {
+	return tv.hwndNormal
+}
+
 // Dispose releases the operating system resources, associated with the
 // *TableView.
 func (tv *TableView) Dispose() {
+	tv.endCellEdit(false)
+	tv.endRowDrag(false)
+
 	tv.columns.unsetColumnsTV()
 
+	if tv.headerToolTip != nil {
+		tv.headerToolTip.Dispose()
+		tv.headerToolTip = nil
+	}
+
 	tv.disposeImageListAndCaches()
+	tv.disposeRowHeightImageList()
+
+	if tv.hImlCheckState != 0 {
+		tv.releaseCheckStateImageList(tv.hwndFrozen)
+		tv.releaseCheckStateImageList(tv.hwndNormal)
+
+		win.ImageList_Destroy(tv.hImlCheckState)
+		tv.hImlCheckState = 0
+	}
+	if tv.hImlHeader != 0 {
+		win.ImageList_Destroy(tv.hImlHeader)
+		tv.hImlHeader = 0
+	}
 
 	if tv.hWnd != 0 {
 		if !win.KillTimer(tv.hWnd, tableViewCurrentIndexChangedTimerId) {
@@ -262,6 +590,27 @@ func (tv *TableView) Dispose() {
 		if !win.KillTimer(tv.hWnd, tableViewSelectedIndexesChangedTimerId) {
 			lastError("KillTimer")
 		}
+		if !win.KillTimer(tv.hWnd, tableViewColumnsOrderChangedTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewSelectionVetoedTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewVisibleRangeChangedTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewItemHoveredTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewStyledRowHeightTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewCellEditCommitTimerId) {
+			lastError("KillTimer")
+		}
+		if !win.KillTimer(tv.hWnd, tableViewItemFlashTimerId) {
+			lastError("KillTimer")
+		}
 	}
 
 	if tv.hwndFrozen != 0 {
@@ -304,8 +653,9 @@ func (tv *TableView) SizeHint() Size {
 func (tv *TableView) applyEnabled(enabled bool) {
 	tv.WidgetBase.applyEnabled(enabled)
 
-	win.EnableWindow(tv.hwndFrozen, enabled)
-	win.EnableWindow(tv.hwndNormal, enabled)
+	// A busy *TableView keeps its list views disabled.
+	win.EnableWindow(tv.hwndFrozen, enabled && !tv.busy)
+	win.EnableWindow(tv.hwndNormal, enabled && !tv.busy)
 }
 
 func (tv *TableView) applyFont(font *Font) {
@@ -315,6 +665,58 @@ func (tv *TableView) applyFont(font *Font) {
 
 	win.SendMessage(tv.hwndFrozen, win.WM_SETFONT, hFont, 0)
 	win.SendMessage(tv.hwndNormal, win.WM_SETFONT, hFont, 0)
+
+	// The list views pass their font on to their headers.
+	if tv.headerFont != nil {
+		tv.applyHeaderFont()
+	}
+}
+
+// HeaderFont returns the *Font of the column headers, or nil if the headers
+// use the font of the *TableView.
+func (tv *TableView) HeaderFont() *Font {
+	return tv.headerFont
+}
+
+// SetHeaderFont sets the *Font of the column headers.
+//
+// Pass nil to use the font of the *TableView.
+func (tv *TableView) SetHeaderFont(font *Font) {
+	if font == tv.headerFont {
+		return
+	}
+
+	tv.headerFont = font
+
+	tv.applyHeaderFont()
+}
+
+func (tv *TableView) applyHeaderFont() {
+	font := tv.headerFont
+	if font == nil {
+		font = tv.Font()
+	}
+
+	hFont := uintptr(font.handleForDPI(0))
+
+	for _, hwnd := range [...]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+		win.SendMessage(headerHwnd, win.WM_SETFONT, hFont, 1)
+	}
+
+	tv.updateLVSizes()
+}
+
+// HeaderHeight returns the height of the column headers in pixels.
+func (tv *TableView) HeaderHeight() int {
+	headerHwnd := win.HWND(win.SendMessage(tv.hwndNormal, win.LVM_GETHEADER, 0, 0))
+
+	var rc win.RECT
+	if !win.GetWindowRect(headerHwnd, &rc) {
+		return 0
+	}
+
+	return int(rc.Bottom - rc.Top)
 }
 
 // ColumnsOrderable returns if the user can reorder columns by dragging and
@@ -426,6 +828,9 @@ func (tv *TableView) AlternatingRowBGColor() Color {
 }
 
 // SetAlternatingRowBGColor sets the alternating row background color.
+//
+// A CellStyler can override the background color of a row by setting
+// BackgroundColor when being called for the whole row, i.e. with Col() == -1.
 func (tv *TableView) SetAlternatingRowBGColor(c Color) {
 	tv.alternatingRowBGColor = c
 
@@ -434,944 +839,4360 @@ func (tv *TableView) SetAlternatingRowBGColor(c Color) {
 	tv.Invalidate()
 }
 
+// SelectedRowBGColor returns the background color of selected rows, that was
+// set using SetSelectedRowBGColor.
+func (tv *TableView) SelectedRowBGColor() Color {
+	return tv.selectedRowBGColor
+}
+
+// SetSelectedRowBGColor sets the background color of selected rows.
+//
+// A value of 0 means the system selection color is used.
+func (tv *TableView) SetSelectedRowBGColor(c Color) {
+	tv.selectedRowBGColor = c
+
+	tv.Invalidate()
+}
+
+// SelectedRowTextColor returns the text color of selected rows, that was set
+// using SetSelectedRowTextColor.
+func (tv *TableView) SelectedRowTextColor() Color {
+	return tv.selectedRowTextColor
+}
+
+// SetSelectedRowTextColor sets the text color of selected rows.
+//
+// A value of 0 means the system selection text color is used.
+func (tv *TableView) SetSelectedRowTextColor(c Color) {
+	tv.selectedRowTextColor = c
+
+	tv.Invalidate()
+}
+
+// selectionColors returns the colors custom draw uses for selected rows.
+func (tv *TableView) selectionColors() (bg, text Color) {
+	bg, text = tv.selectedRowBGColor, tv.selectedRowTextColor
+
+	if bg == 0 {
+		bg = Color(win.GetSysColor(win.COLOR_HIGHLIGHT))
+	}
+	if text == 0 {
+		text = Color(win.GetSysColor(win.COLOR_HIGHLIGHTTEXT))
+	}
+
+	return
+}
+
 // Columns returns the list of columns.
 func (tv *TableView) Columns() *TableViewColumnList {
 	return tv.columns
 }
 
-// VisibleColumnsInDisplayOrder returns a slice of visible columns in display
-// order.
-func (tv *TableView) VisibleColumnsInDisplayOrder() []*TableViewColumn {
-	visibleCols := tv.visibleColumns()
-	indices := make([]int32, len(visibleCols))
+// ColumnByName returns the column with the specified name, or nil if there is
+// no such column.
+func (tv *TableView) ColumnByName(name string) *TableViewColumn {
+	if i := tv.ColumnIndexByName(name); i > -1 {
+		return tv.columns.items[i]
+	}
 
-	frozenCount := tv.visibleFrozenColumnCount()
-	normalCount := len(visibleCols) - frozenCount
+	return nil
+}
 
-	if frozenCount > 0 {
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_GETCOLUMNORDERARRAY, uintptr(frozenCount), uintptr(unsafe.Pointer(&indices[0]))) {
-			newError("LVM_GETCOLUMNORDERARRAY")
-			return nil
-		}
-	}
-	if normalCount > 0 {
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_GETCOLUMNORDERARRAY, uintptr(normalCount), uintptr(unsafe.Pointer(&indices[frozenCount]))) {
-			newError("LVM_GETCOLUMNORDERARRAY")
-			return nil
+// ColumnIndexByName returns the index of the column with the specified name,
+// or -1 if there is no such column.
+func (tv *TableView) ColumnIndexByName(name string) int {
+	for i, tvc := range tv.columns.items {
+		if tvc.name == name {
+			return i
 		}
 	}
 
-	orderedCols := make([]*TableViewColumn, len(visibleCols))
+	return -1
+}
 
-	for i, j := range indices {
-		if i > frozenCount {
-			j += int32(frozenCount)
+// SetColumnsVisible makes the columns with the specified names visible and
+// hides all other columns, laying out the *TableView only once.
+//
+// Columns that stay visible keep their relative display order. Columns that
+// become visible are displayed after them, in the order of the column list.
+func (tv *TableView) SetColumnsVisible(names []string) error {
+	visible := make(map[*TableViewColumn]bool, len(names))
+	for _, name := range names {
+		tvc := tv.ColumnByName(name)
+		if tvc == nil {
+			return newError(fmt.Sprintf("unknown column: %q", name))
 		}
-		orderedCols[i] = visibleCols[j]
+
+		visible[tvc] = true
 	}
 
-	return orderedCols
-}
+	order := tv.VisibleColumnsInDisplayOrder()
 
-// RowsPerPage returns the number of fully visible rows.
-func (tv *TableView) RowsPerPage() int {
-	return int(win.SendMessage(tv.hwndNormal, win.LVM_GETCOUNTPERPAGE, 0, 0))
-}
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
 
-func (tv *TableView) Invalidate() error {
-	win.InvalidateRect(tv.hwndFrozen, nil, true)
-	win.InvalidateRect(tv.hwndNormal, nil, true)
+	tv.lvSizesUpdateDeferred = true
+	defer func() {
+		tv.lvSizesUpdateDeferred = false
+		tv.updateLVSizes()
+	}()
 
-	return tv.WidgetBase.Invalidate()
+	// Hiding columns first keeps the list views from growing in between.
+	for _, tvc := range tv.columns.items {
+		if !visible[tvc] {
+			if err := tvc.SetVisible(false); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tvc := range tv.columns.items {
+		if visible[tvc] {
+			if err := tvc.SetVisible(true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tv.setColumnDisplayOrder(order)
 }
 
-// UpdateItem ensures the item at index will be redrawn.
+// AddColumns appends cols to the columns of the *TableView, laying it out only
+// once.
 //
-// If the model supports sorting, it will be resorted.
-func (tv *TableView) UpdateItem(index int) error {
-	if s, ok := tv.model.(Sorter); ok {
-		if err := s.Sort(s.SortedColumn(), s.SortOrder()); err != nil {
-			return err
+// Adding n columns through Columns lays out the list views n times, or 2n times
+// while a frozen column count is set, and updates the header images of all
+// columns added so far n times. AddColumns does either once.
+// BenchmarkTableViewColumnsAdd and BenchmarkTableViewAddColumns compare the
+// time both take, TestTableViewAddColumns that they yield the same columns.
+//
+// If an error occurs, the columns inserted so far are kept.
+func (tv *TableView) AddColumns(cols []*TableViewColumn) error {
+	seen := make(map[*TableViewColumn]bool, len(cols))
+	for _, tvc := range cols {
+		if tvc.tv != nil || seen[tvc] {
+			return newError("duplicate insert")
 		}
 
-		return tv.Invalidate()
-	} else {
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_UPDATE, uintptr(index), 0) {
-			return newError("LVM_UPDATE")
-		}
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_UPDATE, uintptr(index), 0) {
-			return newError("LVM_UPDATE")
+		seen[tvc] = true
+	}
+
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	tv.lvSizesUpdateDeferred = true
+	tv.headerImagesUpdateDeferred = true
+	defer func() {
+		tv.lvSizesUpdateDeferred = false
+		tv.headerImagesUpdateDeferred = false
+		tv.updateHeaderImages()
+		tv.updateLVSizes()
+	}()
+
+	for _, tvc := range cols {
+		if err := tv.columns.insert(tv.columns.Len(), tvc, false); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return tv.applyFrozenColumnCount()
 }
 
-func (tv *TableView) attachModel() {
-	tv.rowsResetHandlerHandle = tv.model.RowsReset().Attach(func() {
-		tv.setItemCount()
+// VisibleColumnsInDisplayOrder returns a slice of visible columns in display
+// order.
+func (tv *TableView) VisibleColumnsInDisplayOrder() []*TableViewColumn {
+	frozenCols, normalCols := tv.visibleColumnsInListViewOrder()
 
-		tv.SetCurrentIndex(-1)
-	})
+	offset := tv.frozenColumnOffset()
 
-	tv.rowChangedHandlerHandle = tv.model.RowChanged().Attach(func(row int) {
-		tv.UpdateItem(row)
-	})
+	frozenIndices, err := tv.columnOrderArray(tv.hwndFrozen, len(frozenCols)+int(offset))
+	if err != nil {
+		return nil
+	}
+	normalIndices, err := tv.columnOrderArray(tv.hwndNormal, len(normalCols))
+	if err != nil {
+		return nil
+	}
 
-	tv.rowsInsertedHandlerHandle = tv.model.RowsInserted().Attach(func(from, to int) {
-		i := tv.currentIndex
+	orderedCols := make([]*TableViewColumn, 0, len(frozenCols)+len(normalCols))
 
-		tv.setItemCount()
+	for _, j := range frozenIndices {
+		// The row number column is not a model column.
+		if j -= offset; j > -1 {
+			orderedCols = append(orderedCols, frozenCols[j])
+		}
+	}
+	for _, j := range normalIndices {
+		orderedCols = append(orderedCols, normalCols[j])
+	}
 
-		if from <= i {
-			i += 1 + to - from
+	return orderedCols
+}
+
+// DisplayIndexOf returns the position of the column at index col among the
+// visible columns in display order, or -1 if the column is hidden or there is
+// no such column.
+func (tv *TableView) DisplayIndexOf(col int) int {
+	if col < 0 || col >= tv.columns.Len() || !tv.columns.items[col].visible {
+		return -1
+	}
 
-			tv.SetCurrentIndex(i)
+	tvc := tv.columns.items[col]
+
+	for i, c := range tv.VisibleColumnsInDisplayOrder() {
+		if c == tvc {
+			return i
 		}
-	})
+	}
 
-	tv.rowsRemovedHandlerHandle = tv.model.RowsRemoved().Attach(func(from, to int) {
-		i := tv.currentIndex
+	return -1
+}
 
-		tv.setItemCount()
+// LogicalColumnAtDisplayIndex returns the index of the column, that is at
+// position i among the visible columns in display order, or -1 if there is no
+// such column.
+func (tv *TableView) LogicalColumnAtDisplayIndex(i int) int {
+	cols := tv.VisibleColumnsInDisplayOrder()
+	if i < 0 || i >= len(cols) {
+		return -1
+	}
 
-		index := i
+	return tv.columns.Index(cols[i])
+}
 
-		if from <= i && i <= to {
-			index = -1
-		} else if from < i {
-			index -= 1 + to - from
+// SetColumnDisplayOrder sets the display order of the visible columns,
+// specified by their names.
+//
+// Visible columns that are not specified keep their relative order and are
+// displayed after the specified ones. Frozen columns are always displayed
+// before the other columns.
+func (tv *TableView) SetColumnDisplayOrder(names []string) error {
+	cols := make([]*TableViewColumn, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for i, name := range names {
+		if seen[name] {
+			return newError(fmt.Sprintf("duplicate column name: %q", name))
 		}
+		seen[name] = true
 
-		if index != i {
-			tv.SetCurrentIndex(index)
+		var tvc *TableViewColumn
+		for _, c := range tv.columns.items {
+			if c.name == name {
+				tvc = c
+				break
+			}
 		}
-	})
 
-	if sorter, ok := tv.model.(Sorter); ok {
-		tv.sortChangedHandlerHandle = sorter.SortChanged().Attach(func() {
-			col := sorter.SortedColumn()
-			tv.setSortIcon(col, sorter.SortOrder())
-			tv.Invalidate()
-		})
+		if tvc == nil {
+			return newError(fmt.Sprintf("unknown column name: %q", name))
+		}
+		if !tvc.visible {
+			return newError(fmt.Sprintf("column not visible: %q", name))
+		}
+
+		cols[i] = tvc
 	}
-}
 
-func (tv *TableView) detachModel() {
-	tv.model.RowsReset().Detach(tv.rowsResetHandlerHandle)
-	tv.model.RowChanged().Detach(tv.rowChangedHandlerHandle)
-	tv.model.RowsInserted().Detach(tv.rowsInsertedHandlerHandle)
-	tv.model.RowsRemoved().Detach(tv.rowsRemovedHandlerHandle)
-	if sorter, ok := tv.model.(Sorter); ok {
-		sorter.SortChanged().Detach(tv.sortChangedHandlerHandle)
+	if err := tv.setColumnDisplayOrder(cols); err != nil {
+		return err
 	}
+
+	tv.Invalidate()
+
+	tv.columnsOrderChangedPublisher.Publish()
+
+	return nil
 }
 
-// Model returns the model of the TableView.
-func (tv *TableView) Model() interface{} {
-	return tv.providedModel
+// ColumnFixedPosition returns if the user cannot move the column at index col
+// to another position by dragging its header.
+func (tv *TableView) ColumnFixedPosition(col int) bool {
+	if col < 0 || col >= tv.columns.Len() {
+		return false
+	}
+
+	return tv.columns.items[col].fixedPosition
 }
 
-// SetModel sets the model of the TableView.
+// SetColumnFixedPosition sets if the user cannot move the column at index col
+// to another position by dragging its header.
 //
-// It is required that mdl either implements walk.TableModel,
-// walk.ReflectTableModel or be a slice of pointers to struct or a
-// []map[string]interface{}. A walk.TableModel implementation must also
-// implement walk.Sorter to support sorting, all other options get sorting for
-// free. To support item check boxes and icons, mdl must implement
-// walk.ItemChecker and walk.ImageProvider, respectively. On-demand model
-// population for a walk.ReflectTableModel or slice requires mdl to implement
-// walk.Populator.
-func (tv *TableView) SetModel(mdl interface{}) error {
-	model, ok := mdl.(TableModel)
-	if !ok && mdl != nil {
-		var err error
-		if model, err = newReflectTableModel(mdl); err != nil {
-			if model, err = newMapTableModel(mdl); err != nil {
-				return err
-			}
-		}
+// Other columns cannot be dragged past a column with a fixed position either.
+// Frozen columns always stay in front of the other columns. This does not
+// affect SetColumnDisplayOrder.
+func (tv *TableView) SetColumnFixedPosition(col int, fixed bool) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
 	}
 
-	tv.SetSuspended(true)
-	defer tv.SetSuspended(false)
-
-	if tv.model != nil {
-		tv.detachModel()
+	tv.columns.items[col].fixedPosition = fixed
 
-		tv.disposeImageListAndCaches()
-	}
+	return nil
+}
 
-	oldProvidedModelStyler, _ := tv.providedModel.(CellStyler)
-	if styler, ok := mdl.(CellStyler); ok || tv.styler == oldProvidedModelStyler {
-		tv.styler = styler
+// lvColumnFixed returns if the column at index lvColIdx of list view hwnd must
+// keep its position.
+func (tv *TableView) lvColumnFixed(hwnd win.HWND, lvColIdx int32) bool {
+	col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, lvColIdx)
+	if col == -1 {
+		// The row number column always comes first.
+		return true
 	}
 
-	tv.providedModel = mdl
-	tv.model = model
-
-	tv.itemChecker, _ = model.(ItemChecker)
-	tv.imageProvider, _ = model.(ImageProvider)
+	return tv.columns.items[col].fixedPosition
+}
 
-	if model != nil {
-		tv.attachModel()
+// columnDropAllowed returns if the column at index lvColIdx of list view hwnd
+// may be moved to the position order, without moving a column with a fixed
+// position.
+func (tv *TableView) columnDropAllowed(hwnd win.HWND, lvColIdx, order int32) bool {
+	indices, err := tv.lvColumnOrderArray(hwnd)
+	if err != nil {
+		return false
+	}
 
-		if dms, ok := model.(dataMembersSetter); ok {
-			// FIXME: This depends on columns to be initialized before
-			// calling this method.
-			dataMembers := make([]string, len(tv.columns.items))
+	count := len(indices)
 
-			for i, col := range tv.columns.items {
-				dataMembers[i] = col.DataMemberEffective()
-			}
+	from := lvColumnOrder(indices, lvColIdx)
+	if from == -1 || int(order) >= count {
+		return true
+	}
 
-			dms.setDataMembers(dataMembers)
-		}
+	lo, hi := from, int(order)
+	if lo > hi {
+		lo, hi = hi, lo
+	}
 
-		if sorter, ok := tv.model.(Sorter); ok {
-			sorter.Sort(tv.sortedColumnIndex, tv.sortOrder)
+	// All columns in between shift by one position.
+	for i := lo; i <= hi; i++ {
+		if i != from && tv.lvColumnFixed(hwnd, indices[i]) {
+			return false
 		}
 	}
 
-	tv.SetCurrentIndex(-1)
-
-	return tv.setItemCount()
+	return true
 }
 
-// TableModel returns the TableModel of the TableView.
-func (tv *TableView) TableModel() TableModel {
-	return tv.model
+// SetColumnDragHandler sets a function that decides if the user may move a
+// column by dragging its header.
+//
+// The function is called with the display position of the column, as in
+// VisibleColumnsInDisplayOrder, and a to of -1 when the drag begins, and again
+// with the display position the column would be moved to when it is dropped.
+// Returning false cancels the drag or the drop. Columns with a fixed position
+// can not be moved, regardless of the function. Pass nil to allow all other
+// moves.
+func (tv *TableView) SetColumnDragHandler(f func(from, to int) bool) {
+	tv.columnDragHandler = f
 }
 
-// ItemChecker returns the ItemChecker of the TableView.
-func (tv *TableView) ItemChecker() ItemChecker {
-	return tv.itemChecker
-}
+// lvColumnOrderArray returns the indices of the columns of list view hwnd in
+// display order.
+func (tv *TableView) lvColumnOrderArray(hwnd win.HWND) ([]int32, error) {
+	hwndHeader := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+	count := int(win.SendMessage(hwndHeader, win.HDM_GETITEMCOUNT, 0, 0))
 
-// SetItemChecker sets the ItemChecker of the TableView.
-func (tv *TableView) SetItemChecker(itemChecker ItemChecker) {
-	tv.itemChecker = itemChecker
+	return tv.columnOrderArray(hwnd, count)
 }
 
-// CellStyler returns the CellStyler of the TableView.
-func (tv *TableView) CellStyler() CellStyler {
-	return tv.styler
+// lvColumnOrder returns the position of the column at index lvColIdx within
+// indices, or -1 if it is not there.
+func lvColumnOrder(indices []int32, lvColIdx int32) int {
+	for i, idx := range indices {
+		if idx == lvColIdx {
+			return i
+		}
+	}
+
+	return -1
 }
 
-// SetCellStyler sets the CellStyler of the TableView.
-func (tv *TableView) SetCellStyler(styler CellStyler) {
-	tv.styler = styler
+// columnDisplayPosition returns the position within
+// VisibleColumnsInDisplayOrder of the column at position order in list view
+// hwnd.
+func (tv *TableView) columnDisplayPosition(hwnd win.HWND, order int) int {
+	if hwnd == tv.hwndFrozen {
+		return order - int(tv.frozenColumnOffset())
+	}
+
+	return tv.visibleFrozenColumnCount() + order
 }
 
-func (tv *TableView) setItemCount() error {
-	var count int
+// columnDragAllowed asks the column drag handler, if the column at index
+// lvColIdx of list view hwnd may be moved to position order, or dragged at all
+// if order is -1.
+func (tv *TableView) columnDragAllowed(hwnd win.HWND, lvColIdx, order int32) bool {
+	if tv.columnDragHandler == nil {
+		return true
+	}
 
-	if tv.model != nil {
-		count = tv.model.RowCount()
+	indices, err := tv.lvColumnOrderArray(hwnd)
+	if err != nil {
+		return false
 	}
 
-	if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOSCROLL) {
-		return newError("SendMessage(LVM_SETITEMCOUNT)")
+	from := lvColumnOrder(indices, lvColIdx)
+	if from == -1 {
+		return true
 	}
-	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOSCROLL) {
-		return newError("SendMessage(LVM_SETITEMCOUNT)")
+
+	to := -1
+	if order > -1 {
+		to = tv.columnDisplayPosition(hwnd, int(order))
 	}
 
-	return nil
+	return tv.columnDragHandler(tv.columnDisplayPosition(hwnd, from), to)
 }
 
-// CheckBoxes returns if the *TableView has check boxes.
-func (tv *TableView) CheckBoxes() bool {
-	var hwnd win.HWND
-	if tv.hasFrozenColumn {
-		hwnd = tv.hwndFrozen
-	} else {
-		hwnd = tv.hwndNormal
-	}
+// ColumnsOrderChanged returns the event that is published after the display
+// order of the columns was changed.
+func (tv *TableView) ColumnsOrderChanged() *Event {
+	return tv.columnsOrderChangedPublisher.Event()
+}
 
-	return win.SendMessage(hwnd, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)&win.LVS_EX_CHECKBOXES > 0
+// FrozenColumnCount returns the number of visible frozen columns.
+func (tv *TableView) FrozenColumnCount() int {
+	return tv.visibleFrozenColumnCount()
 }
 
-// SetCheckBoxes sets if the *TableView has check boxes.
-func (tv *TableView) SetCheckBoxes(checkBoxes bool) {
-	var hwnd, hwndOther win.HWND
-	if tv.hasFrozenColumn {
-		hwnd, hwndOther = tv.hwndFrozen, tv.hwndNormal
-	} else {
-		hwnd, hwndOther = tv.hwndNormal, tv.hwndFrozen
+// SetFrozenColumnCount freezes the first count visible columns and unfreezes
+// the rest.
+//
+// While a count is set, it takes precedence over the Frozen flags of the
+// individual columns and is reapplied when columns are added or shown. Pass a
+// negative count to go back to freezing columns individually.
+func (tv *TableView) SetFrozenColumnCount(count int) error {
+	if count < 0 {
+		tv.frozenColumnCount = -1
+		return nil
 	}
 
-	exStyle := win.SendMessage(hwnd, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)
-	oldStyle := exStyle
-	if checkBoxes {
-		exStyle |= win.LVS_EX_CHECKBOXES
-	} else {
-		exStyle &^= win.LVS_EX_CHECKBOXES
-	}
-	if exStyle != oldStyle {
-		win.SendMessage(hwnd, win.LVM_SETEXTENDEDLISTVIEWSTYLE, 0, exStyle)
+	tv.frozenColumnCount = count
+
+	return tv.applyFrozenColumnCount()
+}
+
+func (tv *TableView) applyFrozenColumnCount() error {
+	if tv.frozenColumnCount < 0 {
+		return nil
 	}
 
-	win.SendMessage(hwndOther, win.LVM_SETEXTENDEDLISTVIEWSTYLE, 0, exStyle&^win.LVS_EX_CHECKBOXES)
+	var i int
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible {
+			continue
+		}
 
-	mask := win.SendMessage(hwnd, win.LVM_GETCALLBACKMASK, 0, 0)
+		if err := tvc.SetFrozen(i < tv.frozenColumnCount); err != nil {
+			return err
+		}
 
-	if checkBoxes {
-		mask |= win.LVIS_STATEIMAGEMASK
-	} else {
-		mask &^= win.LVIS_STATEIMAGEMASK
+		i++
 	}
 
-	if win.FALSE == win.SendMessage(hwnd, win.LVM_SETCALLBACKMASK, mask, 0) {
-		newError("SendMessage(LVM_SETCALLBACKMASK)")
-	}
-}
+	tv.hasFrozenColumn = tv.visibleFrozenColumnCount() > 0 || tv.rowNumbersVisible
+	tv.updateLVSizes()
 
-func (tv *TableView) fromLVColIdx(frozen bool, index int32) int {
-	var idx int32
+	return nil
+}
 
-	for i, tvc := range tv.columns.items {
-		if frozen == tvc.frozen && tvc.visible {
-			if idx == index {
-				return i
-			}
+func (tv *TableView) setColumnDisplayOrder(cols []*TableViewColumn) error {
+	var frozenIndices, normalIndices []int32
+	seen := make(map[*TableViewColumn]bool)
 
-			idx++
-		}
+	if tv.rowNumbersVisible {
+		frozenIndices = append(frozenIndices, 0)
 	}
 
-	return -1
-}
+	add := func(tvc *TableViewColumn) {
+		if seen[tvc] || !tvc.visible {
+			return
+		}
+		seen[tvc] = true
 
-func (tv *TableView) toLVColIdx(index int) int32 {
-	var idx int32
+		if tvc.frozen {
+			frozenIndices = append(frozenIndices, tvc.indexInListView())
+		} else {
+			normalIndices = append(normalIndices, tvc.indexInListView())
+		}
+	}
 
-	for i, tvc := range tv.columns.items {
-		if tvc.visible {
-			if i == index {
-				return idx
-			}
+	for _, tvc := range cols {
+		add(tvc)
+	}
+	for _, tvc := range tv.columns.items {
+		add(tvc)
+	}
 
-			idx++
+	if len(frozenIndices) > 0 {
+		if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_SETCOLUMNORDERARRAY, uintptr(len(frozenIndices)), uintptr(unsafe.Pointer(&frozenIndices[0]))) {
+			return newError("LVM_SETCOLUMNORDERARRAY")
+		}
+	}
+	if len(normalIndices) > 0 {
+		if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETCOLUMNORDERARRAY, uintptr(len(normalIndices)), uintptr(unsafe.Pointer(&normalIndices[0]))) {
+			return newError("LVM_SETCOLUMNORDERARRAY")
 		}
 	}
 
-	return -1
+	return nil
 }
 
-func (tv *TableView) visibleFrozenColumnCount() int {
-	var count int
-
+// visibleColumnsInListViewOrder returns the visible frozen and normal columns,
+// each ordered by their index in the respective list view.
+func (tv *TableView) visibleColumnsInListViewOrder() (frozenCols, normalCols []*TableViewColumn) {
 	for _, tvc := range tv.columns.items {
-		if tvc.frozen && tvc.visible {
-			count++
+		if !tvc.visible {
+			continue
+		}
+
+		if tvc.frozen {
+			frozenCols = append(frozenCols, tvc)
+		} else {
+			normalCols = append(normalCols, tvc)
 		}
 	}
 
-	return count
+	return
 }
 
-func (tv *TableView) visibleColumnCount() int {
-	var count int
+func (tv *TableView) columnOrderArray(hwnd win.HWND, count int) ([]int32, error) {
+	indices := make([]int32, count)
 
-	for _, tvc := range tv.columns.items {
-		if tvc.visible {
-			count++
+	if count > 0 {
+		if win.FALSE == win.SendMessage(hwnd, win.LVM_GETCOLUMNORDERARRAY, uintptr(count), uintptr(unsafe.Pointer(&indices[0]))) {
+			return nil, newError("LVM_GETCOLUMNORDERARRAY")
 		}
 	}
 
-	return count
+	return indices, nil
 }
 
-func (tv *TableView) visibleColumns() []*TableViewColumn {
-	var cols []*TableViewColumn
+// AlwaysShowVerticalScrollBar returns if the *TableView always displays the
+// vertical scroll bar.
+func (tv *TableView) AlwaysShowVerticalScrollBar() bool {
+	return tv.alwaysShowVerticalScrollBar
+}
 
-	for _, tvc := range tv.columns.items {
-		if tvc.visible {
-			cols = append(cols, tvc)
-		}
+// SetAlwaysShowVerticalScrollBar sets if the *TableView always displays the
+// vertical scroll bar.
+//
+// By default the scroll bar is only displayed when there are more rows than
+// fit on a page, so the width available for columns changes when rows are
+// added or removed. Always displaying it keeps the layout stable.
+func (tv *TableView) SetAlwaysShowVerticalScrollBar(always bool) {
+	if always == tv.alwaysShowVerticalScrollBar {
+		return
 	}
 
-	return cols
-}
+	tv.alwaysShowVerticalScrollBar = always
 
-/*func (tv *TableView) selectedColumnIndex() int {
-	return tv.fromLVColIdx(tv.SendMessage(LVM_GETSELECTEDCOLUMN, 0, 0))
-}*/
+	if !always {
+		// The list view shows the scroll bar again as needed, when its client
+		// area is resized by the frame change.
+		ensureWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.WS_VSCROLL, false)
+		win.SetWindowPos(tv.hwndNormal, 0, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOZORDER|win.SWP_NOACTIVATE|win.SWP_FRAMECHANGED)
+	}
 
-// func (tv *TableView) setSelectedColumnIndex(index int) {
-// 	tv.SendMessage(win.LVM_SETSELECTEDCOLUMN, uintptr(tv.toLVColIdx(index)), 0)
-// }
+	tv.updateLVSizes()
+	tv.applyColumnWidthFractions()
+}
 
-func (tv *TableView) setSortIcon(index int, order SortOrder) error {
-	frozenHeaderHwnd := win.HWND(win.SendMessage(tv.hwndFrozen, win.LVM_GETHEADER, 0, 0))
-	normalHeaderHwnd := win.HWND(win.SendMessage(tv.hwndNormal, win.LVM_GETHEADER, 0, 0))
+// reserveVerticalScrollBar makes the normal list view display a disabled
+// vertical scroll bar, if all rows fit and AlwaysShowVerticalScrollBar is set.
+//
+// The list view hides the scroll bar again whenever it updates it, so this is
+// called after each layout and item count change.
+func (tv *TableView) reserveVerticalScrollBar() {
+	if !tv.alwaysShowVerticalScrollBar || hasWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.WS_VSCROLL) {
+		return
+	}
 
-	idx := int(tv.toLVColIdx(index))
+	var si win.SCROLLINFO
+	si.CbSize = uint32(unsafe.Sizeof(si))
+	si.FMask = win.SIF_PAGE | win.SIF_RANGE | win.SIF_DISABLENOSCROLL
+	si.NPage = 1
 
-	frozenCount := tv.visibleFrozenColumnCount()
+	win.SetScrollInfo(tv.hwndNormal, win.SB_VERT, &si, true)
+}
 
-	for i, col := range tv.visibleColumns() {
-		item := win.HDITEM{
-			Mask: win.HDI_FORMAT,
-		}
+// RowsPerPage returns the number of fully visible rows.
+//
+// As all rows have the same height, this accounts for rows made taller using
+// SetRowHeight or a UniformRowHeighter.
+func (tv *TableView) RowsPerPage() int {
+	return int(win.SendMessage(tv.hwndNormal, win.LVM_GETCOUNTPERPAGE, 0, 0))
+}
 
-		var headerHwnd win.HWND
-		var offset int
-		if col.frozen {
-			headerHwnd = frozenHeaderHwnd
-		} else {
-			headerHwnd = normalHeaderHwnd
-			offset = -frozenCount
-		}
+// RowHeight returns the minimum row height in 1/96 inch, that was set using
+// SetRowHeight.
+//
+// A value of 0 means the default row height is used.
+func (tv *TableView) RowHeight() int {
+	return tv.rowHeight
+}
 
-		iPtr := uintptr(offset + i)
-		itemPtr := uintptr(unsafe.Pointer(&item))
+// SetRowHeight sets the minimum row height in 1/96 inch, which is scaled for
+// the DPI of the screen.
+//
+// Pass 0 to restore the default row height. The underlying list views only
+// support a uniform height for all rows, so if the model implements
+// UniformRowHeighter, the larger of its height and the one set using this
+// method is used.
+//
+// The row height is applied through an image list of the list views. A list
+// view, that displays both item images and check boxes, has no image list to
+// spare, so there rows are only as tall as the item images require.
+func (tv *TableView) SetRowHeight(height int) error {
+	if height < 0 {
+		return newError("height must be >= 0")
+	}
 
-		if win.SendMessage(headerHwnd, win.HDM_GETITEM, iPtr, itemPtr) == 0 {
-			return newError("SendMessage(HDM_GETITEM)")
-		}
+	tv.rowHeight = height
 
-		if i == idx {
-			switch order {
-			case SortAscending:
-				item.Fmt &^= win.HDF_SORTDOWN
-				item.Fmt |= win.HDF_SORTUP
+	tv.updateRowHeight()
 
-			case SortDescending:
-				item.Fmt &^= win.HDF_SORTUP
-				item.Fmt |= win.HDF_SORTDOWN
+	return nil
+}
+
+// CompactMode returns if the *TableView packs its rows tightly.
+func (tv *TableView) CompactMode() bool {
+	return tv.compactMode
+}
+
+// SetCompactMode sets if the *TableView packs its rows tightly, e.g. for dense
+// log displays.
+//
+// In compact mode, rows that are made taller for fonts of a CellStyler, or
+// using SetDefaultRowHeightForFont, get no extra spacing around the text. The
+// same goes for the summary row and pinned rows. Rows never become lower than
+// the list views require for the font of the *TableView though.
+func (tv *TableView) SetCompactMode(compact bool) {
+	if compact == tv.compactMode {
+		return
+	}
+
+	tv.compactMode = compact
+
+	// Row heights for fonts include the spacing, so they must be measured
+	// again.
+	tv.font2RowHeight = nil
+	tv.styledRowHeight = 0
+
+	tv.updateRowHeight()
+	tv.updateLVSizes()
+	tv.Invalidate()
+}
+
+// rowPadding returns the vertical space to add to the height of text, to get
+// the height of a row.
+func (tv *TableView) rowPadding() int {
+	if tv.compactMode {
+		return 2
+	}
+
+	return 6
+}
+
+// SetDefaultRowHeightForFont sets the row height, so that text in font fits.
+//
+// This is useful if a CellStyler uses a font that is larger than the font of
+// the *TableView.
+func (tv *TableView) SetDefaultRowHeightForFont(font *Font) error {
+	if font == nil {
+		return newError("font must not be nil")
+	}
+
+	// The measured height is in pixels already.
+	return tv.SetRowHeight(tv.rowHeightForFont(font) * 96 / screenDPIY)
+}
+
+func (tv *TableView) rowHeightForFont(font *Font) int {
+	if height, ok := tv.font2RowHeight[font]; ok {
+		return height
+	}
+
+	canvas, err := newCanvasFromHWND(tv.hWnd)
+	if err != nil {
+		return 0
+	}
+	defer canvas.Dispose()
+
+	height, err := canvas.fontHeight(font)
+	if err != nil {
+		return 0
+	}
+	height += tv.rowPadding()
+
+	if tv.font2RowHeight == nil {
+		tv.font2RowHeight = make(map[*Font]int)
+	}
+	tv.font2RowHeight[font] = height
+
+	return height
+}
+
+// growRowHeightForStyledFont makes the rows taller later, if a CellStyler
+// uses a font that does not fit.
+func (tv *TableView) growRowHeightForStyledFont(font *Font) {
+	height := tv.rowHeightForFont(font)
+	if height <= tv.styledRowHeight {
+		return
+	}
+
+	tv.styledRowHeight = height
+
+	if height > tv.effectiveRowHeight {
+		// We are painting, so this must wait.
+		if 0 == win.SetTimer(tv.hWnd, tableViewStyledRowHeightTimerId, 1, 0) {
+			lastError("SetTimer")
+		}
+	}
+}
+
+func (tv *TableView) updateRowHeight() {
+	height := maxi(tv.rowHeight, tv.modelRowHeight) * screenDPIY / 96
+
+	tv.setEffectiveRowHeight(maxi(height, tv.styledRowHeight))
+}
+
+// updateModelRowHeight queries the row height from the UniformRowHeighter of
+// the model, which may have changed along with the rows.
+func (tv *TableView) updateModelRowHeight() {
+	tv.modelRowHeight = 0
+
+	if tv.uniformRowHeighter != nil {
+		tv.modelRowHeight = tv.uniformRowHeighter.UniformRowHeight()
+	}
+
+	tv.updateRowHeight()
+}
+
+func (tv *TableView) setEffectiveRowHeight(height int) {
+	if height == tv.effectiveRowHeight {
+		return
+	}
+
+	tv.effectiveRowHeight = height
+
+	oldHIml := tv.hImlRowHeight
+
+	// The item height of a list view in report mode depends on the height of
+	// its image lists, so we use an empty image list of the desired height.
+	if height > 0 {
+		tv.hImlRowHeight = win.ImageList_Create(1, int32(height), win.ILC_COLOR32, 0, 1)
+	} else {
+		tv.hImlRowHeight = 0
+	}
+
+	tv.applyRowHeightImageList()
+
+	if oldHIml != 0 {
+		win.ImageList_Destroy(oldHIml)
+	}
+
+	tv.Invalidate()
+}
+
+func (tv *TableView) applyRowHeightImageList() {
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		if !tv.displaysImages(hwnd) {
+			win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, uintptr(tv.hImlRowHeight))
+		} else if win.SendMessage(hwnd, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)&win.LVS_EX_CHECKBOXES == 0 {
+			// The small image list holds the item images here, so we have
+			// to use the state image list. If this list view also displays
+			// check boxes, the row height is limited by the item images.
+			win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_STATE, uintptr(tv.hImlRowHeight))
+		}
+	}
+}
+
+func (tv *TableView) disposeRowHeightImageList() {
+	if tv.hImlRowHeight == 0 {
+		return
+	}
+
+	hIml := tv.hImlRowHeight
+	tv.hImlRowHeight = 0
+	tv.effectiveRowHeight = 0
+
+	tv.applyRowHeightImageList()
+
+	win.ImageList_Destroy(hIml)
+}
+
+func (tv *TableView) Invalidate() error {
+	win.InvalidateRect(tv.hwndFrozen, nil, true)
+	win.InvalidateRect(tv.hwndNormal, nil, true)
+
+	return tv.WidgetBase.Invalidate()
+}
+
+// ResortOnUpdate returns if the model is resorted when items are updated.
+func (tv *TableView) ResortOnUpdate() bool {
+	return tv.resortOnUpdate
+}
+
+// SetResortOnUpdate sets if the model is resorted when items are updated, e.g.
+// by UpdateItem or when the model publishes RowChanged.
+//
+// By default it is. Disabling it keeps updated rows from jumping around under
+// the cursor, at the cost of the order being stale until the next sort.
+func (tv *TableView) SetResortOnUpdate(resort bool) {
+	tv.resortOnUpdate = resort
+}
+
+// UpdateItem ensures the item at index will be redrawn.
+//
+// If the model supports sorting and ResortOnUpdate is true, it will be
+// resorted.
+func (tv *TableView) UpdateItem(index int) error {
+	if s, ok := tv.model.(Sorter); ok && tv.resortOnUpdate {
+		if err := s.Sort(s.SortedColumn(), s.SortOrder()); err != nil {
+			return err
+		}
+
+		return tv.Invalidate()
+	} else {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_UPDATE, uintptr(index), 0) {
+			return newError("LVM_UPDATE")
+		}
+		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_UPDATE, uintptr(index), 0) {
+			return newError("LVM_UPDATE")
+		}
+	}
+
+	return nil
+}
+
+// UpdateItemRange ensures the items from index from through index to will be
+// redrawn.
+//
+// If the model supports sorting and ResortOnUpdate is true, it will be
+// resorted.
+func (tv *TableView) UpdateItemRange(from, to int) error {
+	if from > to {
+		from, to = to, from
+	}
+
+	if s, ok := tv.model.(Sorter); ok && tv.resortOnUpdate {
+		if err := s.Sort(s.SortedColumn(), s.SortOrder()); err != nil {
+			return err
+		}
+
+		return tv.Invalidate()
+	} else {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_REDRAWITEMS, uintptr(from), uintptr(to)) {
+			return newError("LVM_REDRAWITEMS")
+		}
+		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_REDRAWITEMS, uintptr(from), uintptr(to)) {
+			return newError("LVM_REDRAWITEMS")
+		}
+	}
+
+	return nil
+}
+
+// BeginUpdate starts a batch of model changes.
+//
+// Until the matching call to EndUpdate, the *TableView does not redraw itself
+// and the events published by the model are coalesced, so that the item count
+// is updated and the model is resorted only once when the batch ends. Calls
+// may be nested.
+func (tv *TableView) BeginUpdate() {
+	tv.updateDepth++
+	if tv.updateDepth > 1 {
+		return
+	}
+
+	tv.batchedChanges, tv.batchedReset, tv.batchedRowChange = false, false, false
+	tv.batchCurrentRow = tv.ViewToModelIndex(tv.currentIndex)
+	tv.batchCurrentID = tv.itemIdentity(tv.batchCurrentRow)
+	tv.batchSelectedRows, tv.batchSelectedIDs = nil, nil
+	for _, index := range tv.selectedIndexes {
+		row := tv.ViewToModelIndex(index)
+
+		tv.batchSelectedRows = append(tv.batchSelectedRows, row)
+		if id := tv.itemIdentity(row); id != nil {
+			tv.batchSelectedIDs = append(tv.batchSelectedIDs, id)
+		}
+	}
+
+	tv.endCellEdit(true)
+
+	win.SendMessage(tv.hwndFrozen, win.WM_SETREDRAW, win.FALSE, 0)
+	win.SendMessage(tv.hwndNormal, win.WM_SETREDRAW, win.FALSE, 0)
+}
+
+// EndUpdate ends a batch of model changes started with BeginUpdate.
+//
+// The current and the selected items stay so, without scrolling, unless they
+// were removed. If the model was resorted, items are identified through
+// ItemIDProvider or, for slice based models, by their pointers.
+func (tv *TableView) EndUpdate() {
+	if tv.updateDepth == 0 {
+		return
+	}
+
+	tv.updateDepth--
+	if tv.updateDepth > 0 {
+		return
+	}
+
+	if tv.batchedChanges && tv.model != nil {
+		// Updating the item count may already report selection changes.
+		currentID, selectedIDs := tv.currentItemID, tv.selectedItemIDs
+
+		var sorted bool
+		if s, ok := tv.model.(Sorter); ok && tv.batchedRowChange && tv.resortOnUpdate {
+			s.Sort(s.SortedColumn(), s.SortOrder())
+			sorted = true
+		}
+
+		tv.applyRowFilter()
+		tv.rebuildCheckedRows()
+		tv.setItemCount()
+		if tv.batchedReset {
+			tv.updateModelRowHeight()
+		}
+
+		switch {
+		case tv.batchedReset && tv.preserveSelectionOnReset && tv.itemIDProvider != nil:
+			tv.restoreSelectionByItemIDs(currentID, selectedIDs)
+
+		case tv.batchedReset:
+			tv.SetCurrentIndex(-1)
+
+		default:
+			currentRow, selectedRows := tv.batchCurrentRow, tv.batchSelectedRows
+
+			// Sorting moved the items, so we look them up again. Items
+			// that cannot be identified are deselected.
+			if sorted {
+				currentRow, selectedRows = tv.rowsOfItems(tv.batchCurrentID, tv.batchSelectedIDs)
 			}
+
+			tv.remapSelection(currentRow, selectedRows)
+		}
+
+		tv.batchCurrentID, tv.batchSelectedIDs, tv.batchSelectedRows = nil, nil, nil
+	}
+
+	win.SendMessage(tv.hwndFrozen, win.WM_SETREDRAW, win.TRUE, 0)
+	win.SendMessage(tv.hwndNormal, win.WM_SETREDRAW, win.TRUE, 0)
+
+	tv.Invalidate()
+}
+
+// itemIdentity returns a comparable value, that identifies the item at model
+// row even after the model was sorted, or nil if there is none. It is the
+// ItemID, if the model implements ItemIDProvider, or the item pointer of a
+// slice based model.
+func (tv *TableView) itemIdentity(row int) interface{} {
+	if row < 0 || tv.model == nil || row >= tv.model.RowCount() {
+		return nil
+	}
+
+	if tv.itemIDProvider != nil {
+		return tv.itemIDProvider.ItemID(row)
+	}
+
+	items := tv.reflectItems()
+	if items == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(items)
+	if row >= value.Len() {
+		return nil
+	}
+
+	item := value.Index(row)
+	if item.Kind() == reflect.Interface {
+		item = item.Elem()
+	}
+	if item.Kind() != reflect.Ptr {
+		return nil
+	}
+
+	return item.Pointer()
+}
+
+// rowsOfItems returns the current model rows of the items identified by
+// currentID and selectedIDs, as returned by itemIdentity.
+func (tv *TableView) rowsOfItems(currentID interface{}, selectedIDs []interface{}) (currentRow int, selectedRows []int) {
+	currentRow = -1
+
+	if currentID == nil && len(selectedIDs) == 0 {
+		return
+	}
+
+	selected := make(map[interface{}]bool, len(selectedIDs))
+	for _, id := range selectedIDs {
+		selected[id] = true
+	}
+
+	for row, count := 0, tv.model.RowCount(); row < count; row++ {
+		id := tv.itemIdentity(row)
+		if id == nil {
+			continue
+		}
+
+		if id == currentID {
+			currentRow = row
+		}
+		if selected[id] {
+			selectedRows = append(selectedRows, row)
+		}
+	}
+
+	return
+}
+
+func (tv *TableView) attachModel() {
+	tv.rowsResetHandlerHandle = tv.model.RowsReset().Attach(func() {
+		tv.cancelItemFlashes()
+		tv.checkAnchorIndex = -1
+
+		if tv.updateDepth > 0 {
+			tv.batchedChanges, tv.batchedReset = true, true
+			tv.batchCurrentRow = -1
+			return
+		}
+
+		// Updating the item count may already report selection changes.
+		currentID, selectedIDs := tv.currentItemID, tv.selectedItemIDs
+
+		tv.applyRowFilter()
+		tv.setItemCount()
+
+		tv.updateModelRowHeight()
+		tv.rebuildCheckedRows()
+
+		if tv.preserveSelectionOnReset && tv.itemIDProvider != nil {
+			tv.restoreSelectionByItemIDs(currentID, selectedIDs)
 		} else {
-			item.Fmt &^= win.HDF_SORTDOWN | win.HDF_SORTUP
+			tv.SetCurrentIndex(-1)
+		}
+	})
+
+	tv.rowChangedHandlerHandle = tv.model.RowChanged().Attach(func(row int) {
+		tv.updateModelRowHeight()
+
+		if tv.updateDepth > 0 {
+			tv.batchedChanges, tv.batchedRowChange = true, true
+			return
+		}
+
+		if tv.rowFilter == nil {
+			tv.UpdateItem(row)
+			tv.updateCheckedRows(row, row)
+			tv.invalidateSummaryRow()
+			tv.updatePinnedRows()
+			return
+		}
+
+		tv.refilterRows(row, row)
+	})
+
+	if rc, ok := tv.model.(RowsChanger); ok {
+		tv.rowsChangedHandlerHandle = rc.RowsChanged().Attach(func(from, to int) {
+			tv.updateModelRowHeight()
+
+			if tv.updateDepth > 0 {
+				tv.batchedChanges, tv.batchedRowChange = true, true
+				return
+			}
+
+			if tv.rowFilter == nil {
+				tv.UpdateItemRange(from, to)
+				tv.updateCheckedRows(from, to)
+				tv.invalidateSummaryRow()
+				tv.updatePinnedRows()
+				return
+			}
+
+			tv.refilterRows(from, to)
+		})
+	}
+
+	tv.rowsInsertedHandlerHandle = tv.model.RowsInserted().Attach(func(from, to int) {
+		tv.shiftItemFlashes(from, 1+to-from)
+		tv.updateModelRowHeight()
+
+		if tv.updateDepth > 0 {
+			tv.batchedChanges = true
+			if from <= tv.batchCurrentRow {
+				tv.batchCurrentRow += 1 + to - from
+			}
+			for i, row := range tv.batchSelectedRows {
+				if from <= row {
+					tv.batchSelectedRows[i] += 1 + to - from
+				}
+			}
+			return
+		}
+
+		i := tv.ViewToModelIndex(tv.currentIndex)
+
+		tv.applyRowFilter()
+		tv.insertCheckedRows(from, to)
+		tv.setItemCount()
+
+		if from <= i {
+			i += 1 + to - from
+
+			tv.setCurrentIndexOrNone(tv.ModelToViewIndex(i))
+		}
+	})
+
+	tv.rowsRemovedHandlerHandle = tv.model.RowsRemoved().Attach(func(from, to int) {
+		tv.shiftItemFlashes(from, -(1 + to - from))
+		tv.updateModelRowHeight()
+
+		if tv.updateDepth > 0 {
+			tv.batchedChanges = true
+			if from <= tv.batchCurrentRow && tv.batchCurrentRow <= to {
+				tv.batchCurrentRow = -1
+			} else if from < tv.batchCurrentRow {
+				tv.batchCurrentRow -= 1 + to - from
+			}
+			rows := tv.batchSelectedRows[:0]
+			for _, row := range tv.batchSelectedRows {
+				if row < from {
+					rows = append(rows, row)
+				} else if row > to {
+					rows = append(rows, row-(1+to-from))
+				}
+			}
+			tv.batchSelectedRows = rows
+			return
+		}
+
+		i := tv.ViewToModelIndex(tv.currentIndex)
+
+		tv.removeCheckedRows(from, to)
+		tv.applyRowFilter()
+		tv.setItemCount()
+
+		index := i
+
+		if from <= i && i <= to {
+			index = -1
+		} else if from < i {
+			index -= 1 + to - from
+		}
+
+		if index != i {
+			tv.setCurrentIndexOrNone(tv.ModelToViewIndex(index))
+		}
+	})
+
+	if sorter, ok := tv.model.(Sorter); ok {
+		tv.sortChangedHandlerHandle = sorter.SortChanged().Attach(func() {
+			if tv.rowFilter != nil {
+				tv.applyRowFilter()
+			}
+			tv.rebuildCheckedRows()
+			tv.remapItemFlashes()
+
+			if ms, ok := sorter.(MultiSorter); ok {
+				tv.setSortIcons(ms.SortedColumns(), ms.SortOrders())
+			} else {
+				col := sorter.SortedColumn()
+				tv.SetSortIndicator(col, sorter.SortOrder())
+			}
+			tv.Invalidate()
+		})
+	}
+}
+
+// refilterRows checks again if the model rows from through to match the row
+// filter, after they changed. Only these rows are added to or removed from the
+// displayed rows, and the selection stays with its rows, without scrolling.
+func (tv *TableView) refilterRows(from, to int) {
+	if tv.model == nil {
+		return
+	}
+
+	from = maxi(0, from)
+	to = mini(tv.model.RowCount()-1, to)
+
+	currentRow := tv.ViewToModelIndex(tv.currentIndex)
+	selectedRows := make([]int, len(tv.selectedIndexes))
+	for i, index := range tv.selectedIndexes {
+		selectedRows[i] = tv.ViewToModelIndex(index)
+	}
+
+	tv.updateCheckedRows(from, to)
+
+	var changed bool
+	for row := from; row <= to; row++ {
+		i := sort.SearchInts(tv.filteredRows, row)
+		displayed := i < len(tv.filteredRows) && tv.filteredRows[i] == row
+
+		switch matches := tv.rowFilter(row); {
+		case matches && !displayed:
+			tv.filteredRows = append(tv.filteredRows, 0)
+			copy(tv.filteredRows[i+1:], tv.filteredRows[i:])
+			tv.filteredRows[i] = row
+			tv.refilterCheckedRow(row, true)
+			changed = true
+
+		case !matches && displayed:
+			tv.filteredRows = append(tv.filteredRows[:i], tv.filteredRows[i+1:]...)
+			tv.refilterCheckedRow(row, false)
+			changed = true
+		}
+	}
+
+	if !changed {
+		first := sort.SearchInts(tv.filteredRows, from)
+		last := sort.SearchInts(tv.filteredRows, to+1) - 1
+		if first <= last {
+			win.SendMessage(tv.hwndFrozen, win.LVM_REDRAWITEMS, uintptr(first), uintptr(last))
+			win.SendMessage(tv.hwndNormal, win.LVM_REDRAWITEMS, uintptr(first), uintptr(last))
+		}
+
+		tv.invalidateSummaryRow()
+		tv.updatePinnedRows()
+		return
+	}
+
+	tv.setItemCount()
+	tv.remapSelection(currentRow, selectedRows)
+
+	tv.Invalidate()
+}
+
+// remapSelection selects the displayed items of the model rows selectedRows
+// and makes the one of currentRow the current item, after the displayed rows
+// changed. Unlike SetCurrentIndex, it does not scroll. Events are published
+// only if rows are no longer displayed.
+func (tv *TableView) remapSelection(currentRow int, selectedRows []int) {
+	tv.inSelectionRemap = true
+	defer func() {
+		tv.inSelectionRemap = false
+	}()
+
+	lvi := win.LVITEM{StateMask: win.LVIS_FOCUSED | win.LVIS_SELECTED}
+	setState := func(index int) {
+		win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+		win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+	}
+
+	setState(-1)
+
+	indexes := make([]int, 0, len(selectedRows))
+	lvi.StateMask, lvi.State = win.LVIS_SELECTED, win.LVIS_SELECTED
+	for _, row := range selectedRows {
+		if index := tv.ModelToViewIndex(row); index > -1 {
+			indexes = append(indexes, index)
+			setState(index)
+		}
+	}
+
+	current := tv.ModelToViewIndex(currentRow)
+	if current > -1 {
+		lvi.StateMask, lvi.State = win.LVIS_FOCUSED, win.LVIS_FOCUSED
+		if !tv.MultiSelection() {
+			lvi.StateMask |= win.LVIS_SELECTED
+			lvi.State |= win.LVIS_SELECTED
+		}
+		setState(current)
+
+		win.SendMessage(tv.hwndFrozen, win.LVM_SETSELECTIONMARK, 0, uintptr(current))
+		win.SendMessage(tv.hwndNormal, win.LVM_SETSELECTIONMARK, 0, uintptr(current))
+	}
+
+	tv.currentIndex = current
+	tv.selectedIndexes = indexes
+
+	// The items moved, but they are the same.
+	tv.deltaBaseSelectedIndexes = append([]int(nil), indexes...)
+	sort.Ints(tv.deltaBaseSelectedIndexes)
+
+	tv.invalidatePinnedRows()
+
+	if current == -1 && currentRow != -1 {
+		tv.currentIndexChangedPublisher.Publish()
+	}
+	if len(indexes) != len(selectedRows) {
+		tv.publishSelectedIndexesChanged()
+	}
+}
+
+func (tv *TableView) detachModel() {
+	tv.cancelItemFlashes()
+
+	tv.model.RowsReset().Detach(tv.rowsResetHandlerHandle)
+	tv.model.RowChanged().Detach(tv.rowChangedHandlerHandle)
+	if rc, ok := tv.model.(RowsChanger); ok {
+		rc.RowsChanged().Detach(tv.rowsChangedHandlerHandle)
+	}
+	tv.model.RowsInserted().Detach(tv.rowsInsertedHandlerHandle)
+	tv.model.RowsRemoved().Detach(tv.rowsRemovedHandlerHandle)
+	if sorter, ok := tv.model.(Sorter); ok {
+		sorter.SortChanged().Detach(tv.sortChangedHandlerHandle)
+	}
+}
+
+// Model returns the model of the TableView.
+func (tv *TableView) Model() interface{} {
+	return tv.providedModel
+}
+
+// SetModel sets the model of the TableView.
+//
+// It is required that mdl either implements walk.TableModel,
+// walk.ReflectTableModel or be a slice of pointers to struct or a
+// []map[string]interface{}. A walk.TableModel implementation must also
+// implement walk.Sorter to support sorting, all other options get sorting for
+// free. To support item check boxes and icons, mdl must implement
+// walk.ItemChecker and walk.ImageProvider, respectively. On-demand model
+// population for a walk.ReflectTableModel or slice requires mdl to implement
+// walk.Populator.
+func (tv *TableView) SetModel(mdl interface{}) error {
+	model, ok := mdl.(TableModel)
+	if !ok && mdl != nil {
+		var err error
+		if model, err = newReflectTableModel(mdl); err != nil {
+			if model, err = newMapTableModel(mdl); err != nil {
+				return err
+			}
+		}
+	}
+
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	tv.endCellEdit(false)
+
+	if tv.model != nil {
+		tv.detachModel()
+
+		tv.disposeImageListAndCaches()
+	}
+
+	oldProvidedModelStyler, _ := tv.providedModel.(CellStyler)
+	if styler, ok := mdl.(CellStyler); ok || tv.styler == oldProvidedModelStyler {
+		tv.styler = styler
+	}
+
+	tv.providedModel = mdl
+	tv.model = model
+
+	tv.itemChecker, _ = model.(ItemChecker)
+	if tv.triStateItemChecker, ok = model.(TriStateItemChecker); !ok {
+		tv.triStateItemChecker, _ = mdl.(TriStateItemChecker)
+	}
+	tv.imageProvider, _ = model.(ImageProvider)
+	if tv.cellImageProvider, ok = model.(CellImageProvider); !ok {
+		tv.cellImageProvider, _ = mdl.(CellImageProvider)
+	}
+	if tv.cellSetter, ok = model.(CellSetter); !ok {
+		tv.cellSetter, _ = mdl.(CellSetter)
+	}
+	if tv.uniformRowHeighter, ok = model.(UniformRowHeighter); !ok {
+		tv.uniformRowHeighter, _ = mdl.(UniformRowHeighter)
+	}
+	if tv.rowMover, ok = model.(RowMover); !ok {
+		tv.rowMover, _ = mdl.(RowMover)
+	}
+	if tv.populator, ok = model.(Populator); !ok {
+		tv.populator, _ = mdl.(Populator)
+	}
+	if tv.itemIDProvider, ok = model.(ItemIDProvider); !ok {
+		tv.itemIDProvider, _ = mdl.(ItemIDProvider)
+	}
+	if tv.cellToolTipProvider, ok = model.(CellToolTipProvider); !ok {
+		tv.cellToolTipProvider, _ = mdl.(CellToolTipProvider)
+	}
+	if tv.asyncValuer, ok = model.(AsyncValuer); !ok {
+		tv.asyncValuer, _ = mdl.(AsyncValuer)
+	}
+	if tv.rowSelectability, ok = model.(RowSelectabilityProvider); !ok {
+		tv.rowSelectability, _ = mdl.(RowSelectabilityProvider)
+	}
+	if tv.richTextProvider, ok = model.(RichTextProvider); !ok {
+		tv.richTextProvider, _ = mdl.(RichTextProvider)
+	}
+
+	// Info tips are requested only if there is someone to provide them.
+	var infoTip uintptr
+	if tv.cellToolTipProvider != nil {
+		infoTip = win.LVS_EX_INFOTIP
+	}
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETEXTENDEDLISTVIEWSTYLE, win.LVS_EX_INFOTIP, infoTip)
+	win.SendMessage(tv.hwndNormal, win.LVM_SETEXTENDEDLISTVIEWSTYLE, win.LVS_EX_INFOTIP, infoTip)
+
+	if model != nil {
+		tv.attachModel()
+
+		if dms, ok := model.(dataMembersSetter); ok {
+			// FIXME: This depends on columns to be initialized before
+			// calling this method.
+			dataMembers := make([]string, len(tv.columns.items))
+
+			for i, col := range tv.columns.items {
+				dataMembers[i] = col.DataMemberEffective()
+			}
+
+			dms.setDataMembers(dataMembers)
+		}
+
+		if sorter, ok := tv.model.(Sorter); ok {
+			sorter.Sort(tv.sortedColumnIndex, tv.sortOrder)
+		}
+	}
+
+	tv.updateModelRowHeight()
+	tv.rebuildCheckedRows()
+	tv.applyCheckStateImageList()
+
+	tv.SetCurrentIndex(-1)
+
+	tv.applyRowFilter()
+
+	return tv.setItemCount()
+}
+
+// TableModel returns the TableModel of the TableView.
+func (tv *TableView) TableModel() TableModel {
+	return tv.model
+}
+
+// ItemChecker returns the ItemChecker of the TableView.
+func (tv *TableView) ItemChecker() ItemChecker {
+	return tv.itemChecker
+}
+
+// SetItemChecker sets the ItemChecker of the TableView.
+//
+// If itemChecker also implements TriStateItemChecker, check boxes support the
+// indeterminate state.
+func (tv *TableView) SetItemChecker(itemChecker ItemChecker) {
+	tv.itemChecker = itemChecker
+	tv.triStateItemChecker, _ = itemChecker.(TriStateItemChecker)
+
+	tv.applyCheckStateImageList()
+	tv.rebuildCheckedRows()
+	tv.updateHeaderImages()
+}
+
+// CellStyler returns the CellStyler of the TableView.
+func (tv *TableView) CellStyler() CellStyler {
+	return tv.styler
+}
+
+// SetCellStyler sets the CellStyler of the TableView.
+func (tv *TableView) SetCellStyler(styler CellStyler) {
+	tv.styler = styler
+}
+
+// BoolColumnGlyphs returns the texts that are displayed for true and false
+// values.
+func (tv *TableView) BoolColumnGlyphs() (checked, unchecked string) {
+	return tv.boolCheckedGlyph, tv.boolUncheckedGlyph
+}
+
+// SetBoolColumnGlyphs sets the texts that are displayed for true and false
+// values.
+//
+// By default, a check mark is displayed for true and nothing for false.
+func (tv *TableView) SetBoolColumnGlyphs(checked, unchecked string) error {
+	tv.boolCheckedGlyph = checked
+	tv.boolUncheckedGlyph = unchecked
+
+	return tv.Invalidate()
+}
+
+// ClipboardCopyEnabled returns if pressing Ctrl+C copies the selected rows to
+// the clipboard.
+func (tv *TableView) ClipboardCopyEnabled() bool {
+	return tv.clipboardCopyEnabled
+}
+
+// EmptyText returns the text that is displayed while the *TableView has no
+// rows.
+func (tv *TableView) EmptyText() string {
+	return tv.emptyText
+}
+
+// SetEmptyText sets the text that is displayed centered in the *TableView
+// while it has no rows, e.g. "No results found".
+func (tv *TableView) SetEmptyText(text string) {
+	if text == tv.emptyText {
+		return
+	}
+
+	tv.emptyText = text
+
+	win.InvalidateRect(tv.hwndNormal, nil, true)
+}
+
+func (tv *TableView) drawEmptyText() {
+	var rc win.RECT
+	if !win.GetClientRect(tv.hwndNormal, &rc) {
+		return
+	}
+
+	headerHwnd := win.HWND(win.SendMessage(tv.hwndNormal, win.LVM_GETHEADER, 0, 0))
+	var rcHeader win.RECT
+	if win.GetWindowRect(headerHwnd, &rcHeader) {
+		rc.Top += rcHeader.Bottom - rcHeader.Top
+	}
+
+	canvas, err := newCanvasFromHWND(tv.hwndNormal)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	bounds := rectangleFromRECT(rc)
+	bounds.Y += 8
+	bounds.Height -= 8
+	if bounds.Height <= 0 {
+		return
+	}
+
+	canvas.DrawText(
+		tv.emptyText,
+		tv.Font(),
+		Color(win.GetSysColor(win.COLOR_GRAYTEXT)),
+		bounds,
+		TextCenter|TextWordbreak|TextEndEllipsis)
+}
+
+// SetClipboardCopyEnabled sets if pressing Ctrl+C copies the selected rows to
+// the clipboard.
+func (tv *TableView) SetClipboardCopyEnabled(enabled bool) {
+	tv.clipboardCopyEnabled = enabled
+}
+
+// SelectAllShortcutEnabled returns if pressing Ctrl+A selects all items.
+func (tv *TableView) SelectAllShortcutEnabled() bool {
+	return tv.selectAllShortcut
+}
+
+// SetSelectAllShortcutEnabled sets if pressing Ctrl+A selects all items.
+//
+// The shortcut has no effect in single selection mode. It is enabled by
+// default.
+func (tv *TableView) SetSelectAllShortcutEnabled(enabled bool) {
+	tv.selectAllShortcut = enabled
+}
+
+// CheckBoxesOnly returns if clicking an item toggles its check box, instead of
+// selecting it.
+func (tv *TableView) CheckBoxesOnly() bool {
+	return tv.checkBoxesOnly
+}
+
+// SetCheckBoxesOnly sets if clicking an item with the left mouse button
+// toggles its check box, instead of selecting it. Clicking empty space then
+// keeps the current item. The navigation keys move only the focus rectangle
+// and Space toggles the check box of the focused item.
+//
+// This is meant for choosing items by check boxes only and has no effect
+// unless check boxes are displayed and the model implements ItemChecker.
+func (tv *TableView) SetCheckBoxesOnly(checkBoxesOnly bool) {
+	tv.checkBoxesOnly = checkBoxesOnly
+}
+
+// WrapNavigation returns if pressing Down on the last item makes the first
+// item current and pressing Up on the first item the last one.
+func (tv *TableView) WrapNavigation() bool {
+	return tv.wrapNavigation
+}
+
+// SetWrapNavigation sets if pressing Down on the last item makes the first
+// item current and pressing Up on the first item the last one.
+//
+// Wrapping applies in single selection mode only. It is disabled by default.
+func (tv *TableView) SetWrapNavigation(wrap bool) {
+	tv.wrapNavigation = wrap
+}
+
+func (tv *TableView) wrapsNavigation() bool {
+	return tv.wrapNavigation && !tv.MultiSelection()
+}
+
+// WheelScrollLines returns the number of lines the *TableView scrolls
+// horizontally per notch of the mouse wheel, while Shift is pressed.
+func (tv *TableView) WheelScrollLines() int {
+	return tv.wheelScrollLines
+}
+
+// SetWheelScrollLines sets the number of lines the *TableView scrolls
+// horizontally per notch of the mouse wheel, while Shift is pressed. A line is
+// as wide as an average character.
+//
+// The frozen columns do not scroll. The default is 3 lines, pass 0 to disable
+// horizontal scrolling by mouse wheel.
+func (tv *TableView) SetWheelScrollLines(lines int) error {
+	if lines < 0 {
+		return newError("lines must be >= 0")
+	}
+
+	tv.wheelScrollLines = lines
+
+	return nil
+}
+
+// scrollHorizontallyByWheel scrolls the normal list view for a mouse wheel
+// rotation of delta. Rotating the wheel forward scrolls to the left.
+func (tv *TableView) scrollHorizontallyByWheel(delta int) {
+	if tv.wheelScrollLines == 0 {
+		return
+	}
+
+	const wheelDelta = 120
+
+	// High resolution wheels report fractions of a notch, which add up.
+	tv.wheelDelta += delta
+	notches := tv.wheelDelta / wheelDelta
+	tv.wheelDelta -= notches * wheelDelta
+
+	if notches == 0 {
+		return
+	}
+
+	lineWidth := tv.calculateTextSizeImpl("x").Width
+	dx := -notches * tv.wheelScrollLines * lineWidth
+
+	win.SendMessage(tv.hwndNormal, win.LVM_SCROLL, uintptr(dx), 0)
+}
+
+// CopySelectionToClipboard copies the selected rows to the clipboard as tab
+// separated text.
+//
+// The first line holds the column titles. Visible columns are copied in
+// display order, cells are formatted like they are displayed.
+func (tv *TableView) CopySelectionToClipboard() error {
+	if tv.model == nil {
+		return nil
+	}
+
+	indexes := tv.SelectedIndexes()
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	cols := tv.VisibleColumnsInDisplayOrder()
+
+	var buf bytes.Buffer
+
+	for i, tvc := range cols {
+		if i > 0 {
+			buf.WriteByte('\t')
+		}
+		buf.WriteString(tvc.TitleEffective())
+	}
+	buf.WriteString("\r\n")
+
+	for _, index := range indexes {
+		row := tv.ViewToModelIndex(index)
+		if row == -1 {
+			continue
+		}
+
+		for i, tvc := range cols {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(tv.cellText(row, tv.columns.Index(tvc)))
+		}
+		buf.WriteString("\r\n")
+	}
+
+	return Clipboard().SetText(buf.String())
+}
+
+// ExportCSV writes all displayed rows to w as comma separated values, with the
+// visible columns in display order. Rows are written one at a time, so large
+// models are not held in memory.
+func (tv *TableView) ExportCSV(w io.Writer, includeHeaders bool) error {
+	cols := tv.VisibleColumnsInDisplayOrder()
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	record := make([]string, len(cols))
+
+	if includeHeaders {
+		for i, tvc := range cols {
+			record[i] = tvc.TitleEffective()
+		}
+
+		if err := cw.Write(record); err != nil {
+			return wrapError(err)
+		}
+	}
+
+	if tv.model != nil {
+		colIndexes := make([]int, len(cols))
+		for i, tvc := range cols {
+			colIndexes[i] = tv.columns.Index(tvc)
+		}
+
+		for index, count := 0, tv.itemCount(); index < count; index++ {
+			row := tv.ViewToModelIndex(index)
+
+			for i, col := range colIndexes {
+				record[i] = tv.cellText(row, col)
+			}
+
+			if err := cw.Write(record); err != nil {
+				return wrapError(err)
+			}
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return wrapError(err)
+	}
+
+	return nil
+}
+
+// SetDispInfoCounter sets a counter, that is incremented atomically whenever
+// the *TableView provides display information about an item to one of its
+// list views. Pass nil to stop counting.
+//
+// This is meant for diagnosing excessive repainting. The counter may be read
+// from other goroutines using atomic.LoadUint64, so it must be 64-bit aligned.
+func (tv *TableView) SetDispInfoCounter(counter *uint64) {
+	tv.dispInfoCounter = counter
+}
+
+// PopulateAhead returns the number of items following a requested item, that
+// are populated in advance if the model implements Populator.
+func (tv *TableView) PopulateAhead() int {
+	if tv.populateAhead < 0 {
+		return tv.RowsPerPage()
+	}
+
+	return tv.populateAhead
+}
+
+// SetPopulateAhead sets the number of items following a requested item, that
+// are populated in advance if the model implements Populator.
+//
+// This reduces stalls while scrolling through a lazily populated model. Pass a
+// negative count to populate one page ahead, which is the default, or 0 to
+// only populate items when they are displayed.
+func (tv *TableView) SetPopulateAhead(count int) {
+	tv.populateAhead = count
+}
+
+// populateAheadOf populates the items following the item at index, unless
+// they have been populated already.
+func (tv *TableView) populateAheadOf(index int) {
+	last := mini(index+tv.PopulateAhead(), tv.itemCount()-1)
+	if last <= index {
+		return
+	}
+
+	first := index + 1
+	if index >= tv.populatedFrom && index < tv.populatedTo {
+		if last < tv.populatedTo {
+			return
+		}
+
+		first = tv.populatedTo
+	} else {
+		tv.populatedFrom = first
+	}
+
+	for i := first; i <= last; i++ {
+		tv.populator.Populate(tv.ViewToModelIndex(i))
+	}
+
+	tv.populatedTo = last + 1
+}
+
+// RowCount returns the number of items displayed by the *TableView.
+//
+// If a row filter is set, this may be less than the number of rows of the
+// model.
+func (tv *TableView) RowCount() int {
+	return tv.itemCount()
+}
+
+// RowFilter returns the function that decides which rows of the model are
+// displayed, or nil if all rows are displayed.
+func (tv *TableView) RowFilter() func(row int) bool {
+	return tv.rowFilter
+}
+
+// SetRowFilter sets a function that decides which rows of the model are
+// displayed, without modifying the model. Pass nil to display all rows.
+//
+// The filter is reapplied when the model publishes changes. Row indexes of the
+// TableView, like CurrentIndex and SelectedIndexes, refer to displayed rows.
+// Use ViewToModelIndex and ModelToViewIndex to convert between both.
+func (tv *TableView) SetRowFilter(filter func(row int) bool) error {
+	tv.endCellEdit(false)
+
+	i := tv.ViewToModelIndex(tv.currentIndex)
+
+	tv.rowFilter = filter
+	tv.applyRowFilter()
+	tv.recountCheckedRows()
+
+	if err := tv.setItemCount(); err != nil {
+		return err
+	}
+
+	if err := tv.setCurrentIndexOrNone(tv.ModelToViewIndex(i)); err != nil {
+		return err
+	}
+
+	return tv.Invalidate()
+}
+
+// ViewToModelIndex returns the model row index of the displayed row at index,
+// or -1 if there is no such row.
+func (tv *TableView) ViewToModelIndex(index int) int {
+	if tv.rowFilter == nil {
+		return index
+	}
+
+	if index < 0 || index >= len(tv.filteredRows) {
+		return -1
+	}
+
+	return tv.filteredRows[index]
+}
+
+// ModelToViewIndex returns the displayed row index of the model row at index,
+// or -1 if the row is not displayed.
+func (tv *TableView) ModelToViewIndex(index int) int {
+	if tv.rowFilter == nil {
+		return index
+	}
+
+	if i := sort.SearchInts(tv.filteredRows, index); i < len(tv.filteredRows) && tv.filteredRows[i] == index {
+		return i
+	}
+
+	return -1
+}
+
+func (tv *TableView) applyRowFilter() {
+	tv.filteredRows = tv.filteredRows[:0]
+	tv.populatedFrom, tv.populatedTo = 0, 0
+
+	if tv.rowFilter == nil || tv.model == nil {
+		return
+	}
+
+	for row, count := 0, tv.model.RowCount(); row < count; row++ {
+		if tv.rowFilter(row) {
+			tv.filteredRows = append(tv.filteredRows, row)
+		}
+	}
+}
+
+func (tv *TableView) itemCount() int {
+	if tv.rowFilter != nil {
+		return len(tv.filteredRows)
+	} else if tv.model != nil {
+		return tv.model.RowCount()
+	}
+
+	return 0
+}
+
+func (tv *TableView) setItemCount() error {
+	count := tv.itemCount()
+
+	tv.populatedFrom, tv.populatedTo = 0, 0
+
+	defer tv.scheduleVisibleRangeChanged()
+
+	if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOSCROLL) {
+		return newError("SendMessage(LVM_SETITEMCOUNT)")
+	}
+	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMCOUNT, uintptr(count), win.LVSICF_NOSCROLL) {
+		return newError("SendMessage(LVM_SETITEMCOUNT)")
+	}
+
+	tv.reserveVerticalScrollBar()
+	tv.updateHeaderCheckBoxes()
+	tv.updateRowNumberColumnWidth()
+
+	if tv.emptyText != "" {
+		win.InvalidateRect(tv.hwndNormal, nil, true)
+	}
+
+	tv.invalidateSummaryRow()
+	tv.updatePinnedRows()
+
+	// The hovered item may be gone now.
+	if tv.pendingHoveredIndex >= count {
+		tv.pendingHoveredIndex = -1
+	}
+	if tv.hoveredIndex >= count {
+		tv.hoveredIndex = -1
+		tv.itemHoveredPublisher.Publish(-1)
+	}
+
+	return nil
+}
+
+// FocusRectangleVisible returns if the *TableView always displays the focus
+// rectangle around the current item.
+func (tv *TableView) FocusRectangleVisible() bool {
+	return tv.focusRectangleVisible
+}
+
+// SetFocusRectangleVisible sets if the *TableView always displays the focus
+// rectangle around the current item.
+//
+// By default the focus rectangle is hidden.
+func (tv *TableView) SetFocusRectangleVisible(visible bool) {
+	if visible == tv.focusRectangleVisible {
+		return
+	}
+
+	tv.focusRectangleVisible = visible
+
+	action := win.UIS_SET
+	if visible {
+		action = win.UIS_CLEAR
+	}
+
+	wp := uintptr(win.MAKELONG(uint16(action), win.UISF_HIDEFOCUS))
+
+	win.SendMessage(tv.hwndFrozen, win.WM_UPDATEUISTATE, wp, 0)
+	win.SendMessage(tv.hwndNormal, win.WM_UPDATEUISTATE, wp, 0)
+}
+
+// Gridlines returns if the *TableView displays gridlines around its cells.
+func (tv *TableView) Gridlines() bool {
+	return tv.gridlines
+}
+
+// SetGridlines sets if the *TableView displays gridlines around its cells.
+func (tv *TableView) SetGridlines(gridlines bool) {
+	// Custom drawing asks for this for every item, so we keep it around.
+	tv.gridlines = gridlines
+
+	var exStyle uintptr
+	if gridlines {
+		exStyle = win.LVS_EX_GRIDLINES
+	}
+
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETEXTENDEDLISTVIEWSTYLE, win.LVS_EX_GRIDLINES, exStyle)
+	win.SendMessage(tv.hwndNormal, win.LVM_SETEXTENDEDLISTVIEWSTYLE, win.LVS_EX_GRIDLINES, exStyle)
+}
+
+// CheckBoxes returns if the *TableView has check boxes.
+func (tv *TableView) CheckBoxes() bool {
+	var hwnd win.HWND
+	if tv.hasFrozenColumn {
+		hwnd = tv.hwndFrozen
+	} else {
+		hwnd = tv.hwndNormal
+	}
+
+	return win.SendMessage(hwnd, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)&win.LVS_EX_CHECKBOXES > 0
+}
+
+// SetCheckBoxes sets if the *TableView has check boxes.
+func (tv *TableView) SetCheckBoxes(checkBoxes bool) {
+	var hwnd, hwndOther win.HWND
+	if tv.hasFrozenColumn {
+		hwnd, hwndOther = tv.hwndFrozen, tv.hwndNormal
+	} else {
+		hwnd, hwndOther = tv.hwndNormal, tv.hwndFrozen
+	}
+
+	// A list view destroys its state image list along with its check boxes.
+	tv.releaseCheckStateImageList(hwndOther)
+	if !checkBoxes {
+		tv.releaseCheckStateImageList(hwnd)
+	}
+
+	exStyle := win.SendMessage(hwnd, win.LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0)
+	oldStyle := exStyle
+	if checkBoxes {
+		exStyle |= win.LVS_EX_CHECKBOXES
+	} else {
+		exStyle &^= win.LVS_EX_CHECKBOXES
+	}
+	if exStyle != oldStyle {
+		win.SendMessage(hwnd, win.LVM_SETEXTENDEDLISTVIEWSTYLE, 0, exStyle)
+	}
+
+	win.SendMessage(hwndOther, win.LVM_SETEXTENDEDLISTVIEWSTYLE, 0, exStyle&^win.LVS_EX_CHECKBOXES)
+
+	mask := win.SendMessage(hwnd, win.LVM_GETCALLBACKMASK, 0, 0)
+
+	if checkBoxes {
+		mask |= win.LVIS_STATEIMAGEMASK
+	} else {
+		mask &^= win.LVIS_STATEIMAGEMASK
+	}
+
+	if win.FALSE == win.SendMessage(hwnd, win.LVM_SETCALLBACKMASK, mask, 0) {
+		newError("SendMessage(LVM_SETCALLBACKMASK)")
+	}
+
+	if tv.hImlRowHeight != 0 {
+		tv.applyRowHeightImageList()
+	}
+
+	if checkBoxes {
+		tv.applyCheckStateImageList()
+	}
+}
+
+// applyCheckStateImageList replaces the state image list, that a list view
+// creates for its check boxes, with one that also contains an image for the
+// indeterminate state.
+func (tv *TableView) applyCheckStateImageList() {
+	if tv.triStateItemChecker == nil || !tv.CheckBoxes() {
+		return
+	}
+
+	var hwnd win.HWND
+	if tv.hasFrozenColumn {
+		hwnd = tv.hwndFrozen
+	} else {
+		hwnd = tv.hwndNormal
+	}
+
+	if tv.hImlCheckState == 0 {
+		if tv.hImlCheckState = tv.newCheckStateImageList(); tv.hImlCheckState == 0 {
+			return
+		}
+	}
+
+	if win.HIMAGELIST(win.SendMessage(hwnd, lvmGetImageList, win.LVSIL_STATE, 0)) == tv.hImlCheckState {
+		return
+	}
+
+	hImlOld := win.HIMAGELIST(win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_STATE, uintptr(tv.hImlCheckState)))
+	if hImlOld != 0 && hImlOld != tv.hImlRowHeight {
+		win.ImageList_Destroy(hImlOld)
+	}
+}
+
+// releaseCheckStateImageList takes the check state image list back from list
+// view hwnd, so that it does not destroy it. It is destroyed only in Dispose.
+func (tv *TableView) releaseCheckStateImageList(hwnd win.HWND) {
+	if tv.hImlCheckState == 0 {
+		return
+	}
+
+	if win.HIMAGELIST(win.SendMessage(hwnd, lvmGetImageList, win.LVSIL_STATE, 0)) == tv.hImlCheckState {
+		win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_STATE, 0)
+	}
+}
+
+// newCheckStateImageList returns an image list with images of an unchecked,
+// a checked and an indeterminate check box, in that order.
+func (tv *TableView) newCheckStateImageList() win.HIMAGELIST {
+	if !win.IsAppThemed() {
+		return 0
+	}
+
+	hTheme := win.OpenThemeData(tv.hwndFrozen, syscall.StringToUTF16Ptr("Button"))
+	if hTheme == 0 {
+		return 0
+	}
+	defer win.CloseThemeData(hTheme)
+
+	var s win.SIZE
+	if win.S_OK != win.GetThemePartSize(hTheme, 0, win.BP_CHECKBOX, win.CBS_UNCHECKEDNORMAL, nil, win.TS_TRUE, &s) {
+		return 0
+	}
+
+	hIml := win.ImageList_Create(s.CX, s.CY, win.ILC_COLOR32, 3, 0)
+	if hIml == 0 {
+		return 0
+	}
+
+	for _, state := range []int32{win.CBS_UNCHECKEDNORMAL, win.CBS_CHECKEDNORMAL, win.CBS_MIXEDNORMAL} {
+		bmp, err := NewBitmapWithTransparentPixels(Size{int(s.CX), int(s.CY)})
+		if err != nil {
+			win.ImageList_Destroy(hIml)
+			return 0
+		}
+
+		canvas, err := NewCanvasFromImage(bmp)
+		if err == nil {
+			rc := win.RECT{Right: s.CX, Bottom: s.CY}
+			win.DrawThemeBackground(hTheme, canvas.HDC(), win.BP_CHECKBOX, state, &rc, nil)
+			canvas.Dispose()
+
+			win.ImageList_Add(hIml, bmp.hBmp, 0)
+		}
+
+		bmp.Dispose()
+
+		if err != nil {
+			win.ImageList_Destroy(hIml)
+			return 0
+		}
+	}
+
+	return hIml
+}
+
+func (tv *TableView) fromLVColIdx(frozen bool, index int32) int {
+	var idx int32
+	if frozen {
+		idx = tv.frozenColumnOffset()
+	}
+
+	for i, tvc := range tv.columns.items {
+		if frozen == tvc.frozen && tvc.visible {
+			if idx == index {
+				return i
+			}
+
+			idx++
+		}
+	}
+
+	return -1
+}
+
+// checkColumnIndex returns an error, if col is not the index of a column.
+func (tv *TableView) checkColumnIndex(col int) error {
+	if col < 0 || col >= tv.columns.Len() {
+		return newError(fmt.Sprintf("col out of range: %d (column count: %d)", col, tv.columns.Len()))
+	}
+
+	return nil
+}
+
+// ColumnWidth returns the width of the column at index col in pixels.
+func (tv *TableView) ColumnWidth(col int) (int, error) {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return 0, err
+	}
+
+	return tv.columns.items[col].Width(), nil
+}
+
+// SetColumnWidth sets the width of the column at index col in pixels.
+//
+// The width of a hidden column is applied when the column is displayed.
+func (tv *TableView) SetColumnWidth(col, width int) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+	if width < 0 {
+		return newError("width must be >= 0")
+	}
+
+	tvc := tv.columns.items[col]
+
+	// The user may have resized the column since its width was last set.
+	tvc.width = tvc.Width()
+
+	return tvc.SetWidth(width)
+}
+
+func (tv *TableView) toLVColIdx(index int) int32 {
+	if index < 0 || index >= tv.columns.Len() || !tv.columns.items[index].visible {
+		return -1
+	}
+
+	return tv.columns.items[index].indexInListView()
+}
+
+// FrozenColumns returns the columns that are frozen, in the order of the
+// column list. This includes hidden columns, that are frozen once they are
+// displayed.
+func (tv *TableView) FrozenColumns() []*TableViewColumn {
+	var cols []*TableViewColumn
+
+	for _, tvc := range tv.columns.items {
+		if tvc.frozen {
+			cols = append(cols, tvc)
+		}
+	}
+
+	return cols
+}
+
+// HasFrozenColumn returns if the *TableView displays an area of frozen
+// columns, that does not scroll horizontally. This is also the case if only
+// the row number column is displayed there.
+func (tv *TableView) HasFrozenColumn() bool {
+	return tv.hasFrozenColumn
+}
+
+func (tv *TableView) visibleFrozenColumnCount() int {
+	var count int
+
+	for _, tvc := range tv.columns.items {
+		if tvc.frozen && tvc.visible {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (tv *TableView) visibleColumnCount() int {
+	var count int
+
+	for _, tvc := range tv.columns.items {
+		if tvc.visible {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (tv *TableView) visibleColumns() []*TableViewColumn {
+	var cols []*TableViewColumn
+
+	for _, tvc := range tv.columns.items {
+		if tvc.visible {
+			cols = append(cols, tvc)
+		}
+	}
+
+	return cols
+}
+
+/*func (tv *TableView) selectedColumnIndex() int {
+	return tv.fromLVColIdx(tv.SendMessage(LVM_GETSELECTEDCOLUMN, 0, 0))
+}*/
+
+// func (tv *TableView) setSelectedColumnIndex(index int) {
+// 	tv.SendMessage(win.LVM_SETSELECTEDCOLUMN, uintptr(tv.toLVColIdx(index)), 0)
+// }
+
+// SortedColumn returns the index of the column the user sorted the *TableView
+// by, the primary one if sorted by multiple columns.
+func (tv *TableView) SortedColumn() int {
+	return tv.sortedColumnIndex
+}
+
+// SortOrder returns the order the user sorted the *TableView in.
+func (tv *TableView) SortOrder() SortOrder {
+	return tv.sortOrder
+}
+
+// SetSortFunc sets a function, that sorts the model by the column at index col
+// in order, when the user clicks a column header. This allows models that do
+// not implement Sorter to be sorted by header clicks.
+//
+// The function is not called for models that implement Sorter. If it returns
+// an error, the sorted column and order stay as they were. Otherwise the
+// *TableView updates the sort indicator and redraws its items. Pass nil to
+// disable sorting by header clicks again.
+func (tv *TableView) SetSortFunc(f func(col int, order SortOrder) error) {
+	tv.sortFunc = f
+	tv.sortedBySortFunc = false
+}
+
+// SetInitialSort sets the column and order, that a model implementing Sorter
+// is sorted by when it is set, unless state restored by RestoreState says
+// otherwise. By default, it is sorted by the first column in ascending order.
+//
+// The column must have been added already. If such a model is set already,
+// it is sorted right away.
+func (tv *TableView) SetInitialSort(col int, order SortOrder) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tv.sortedColumnIndex = col
+	tv.sortOrder = order
+	tv.initialSortColumn = col
+	tv.initialSortOrder = order
+
+	if sorter, ok := tv.model.(Sorter); ok && sorter.ColumnSortable(col) {
+		return sorter.Sort(col, order)
+	}
+
+	return nil
+}
+
+// SetInitialSortByName is like SetInitialSort, but specifies the column by its
+// name.
+func (tv *TableView) SetInitialSortByName(name string, order SortOrder) error {
+	col := tv.ColumnIndexByName(name)
+	if col == -1 {
+		return newError(fmt.Sprintf("unknown column: %q", name))
+	}
+
+	return tv.SetInitialSort(col, order)
+}
+
+// SortChanged returns the event that is published after the user changed the
+// sorted column or the sort order by clicking a column header.
+func (tv *TableView) SortChanged() *Event {
+	return tv.sortChangedPublisher.Event()
+}
+
+// SetSortIndicator displays the sort indicator for order in the header of the
+// column at index col and removes it from all other columns, without sorting.
+//
+// This is useful if sorting is done elsewhere, e.g. by a server. Models that
+// implement Sorter get their sort indicator updated automatically.
+func (tv *TableView) SetSortIndicator(col int, order SortOrder) error {
+	if col < -1 || col >= tv.columns.Len() {
+		return newError("col out of range")
+	}
+
+	return tv.setSortIcons([]int{col}, []SortOrder{order})
+}
+
+// ClearSortIndicator removes the sort indicator from all column headers.
+func (tv *TableView) ClearSortIndicator() error {
+	return tv.setSortIcons(nil, nil)
+}
+
+func (tv *TableView) setSortIcons(indexes []int, orders []SortOrder) error {
+	frozenHeaderHwnd := win.HWND(win.SendMessage(tv.hwndFrozen, win.LVM_GETHEADER, 0, 0))
+	normalHeaderHwnd := win.HWND(win.SendMessage(tv.hwndNormal, win.LVM_GETHEADER, 0, 0))
+
+	for i, col := range tv.columns.items {
+		if !col.visible {
+			continue
+		}
+
+		item := win.HDITEM{
+			Mask: win.HDI_FORMAT,
+		}
+
+		var headerHwnd win.HWND
+		if col.frozen {
+			headerHwnd = frozenHeaderHwnd
+		} else {
+			headerHwnd = normalHeaderHwnd
+		}
+
+		iPtr := uintptr(col.indexInListView())
+		itemPtr := uintptr(unsafe.Pointer(&item))
+
+		if win.SendMessage(headerHwnd, win.HDM_GETITEM, iPtr, itemPtr) == 0 {
+			return newError("SendMessage(HDM_GETITEM)")
+		}
+
+		item.Fmt &^= win.HDF_SORTDOWN | win.HDF_SORTUP
+
+		for j, index := range indexes {
+			if i != index {
+				continue
+			}
+
+			// A sorter may report fewer orders than columns.
+			order := SortAscending
+			if j < len(orders) {
+				order = orders[j]
+			}
+
+			switch order {
+			case SortAscending:
+				item.Fmt |= win.HDF_SORTUP
+
+			case SortDescending:
+				item.Fmt |= win.HDF_SORTDOWN
+			}
+			break
+		}
+
+		if win.SendMessage(headerHwnd, win.HDM_SETITEM, iPtr, itemPtr) == 0 {
+			return newError("SendMessage(HDM_SETITEM)")
+		}
+	}
+
+	return nil
+}
+
+func (tv *TableView) drawWrappedCellText(hwnd win.HWND, nmlvcd *win.NMLVCUSTOMDRAW, row, col int) {
+	rc := win.RECT{Top: nmlvcd.ISubItem, Left: win.LVIR_LABEL}
+	if 0 == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, nmlvcd.Nmcd.DwItemSpec, uintptr(unsafe.Pointer(&rc))) {
+		return
+	}
+
+	bounds := rectangleFromRECT(rc)
+	bounds.X += 6
+	bounds.Width -= 12
+	bounds.Y += 2
+	bounds.Height -= 4
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return
+	}
+
+	format := TextWordbreak | TextEndEllipsis | TextNoPrefix
+	switch tv.columns.At(col).Alignment() {
+	case AlignCenter:
+		format |= TextCenter
+
+	case AlignFar:
+		format |= TextRight
+	}
+
+	canvas, err := newCanvasFromHDC(nmlvcd.Nmcd.Hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	canvas.DrawText(tv.displayedCellText(row, col), tv.wrapTextFont, tv.wrapTextColor, bounds, format)
+}
+
+// ItemIndexAt returns the index of the item at the specified position, in
+// client coordinates of the *TableView, or -1 if there is no item.
+func (tv *TableView) ItemIndexAt(x, y int) int {
+	_, hti := tv.hitTest(x, y)
+
+	if hti.Flags&win.LVHT_NOWHERE != 0 || hti.IItem < 0 {
+		return -1
+	}
+
+	return int(hti.IItem)
+}
+
+// ColumnIndexAt returns the index of the column of the cell at the specified
+// position, in client coordinates of the *TableView, or -1 if there is no
+// cell.
+func (tv *TableView) ColumnIndexAt(x, y int) int {
+	hwnd, hti := tv.hitTest(x, y)
+
+	if hti.Flags&win.LVHT_NOWHERE != 0 || hti.IItem < 0 {
+		return -1
+	}
+
+	return tv.fromLVColIdx(hwnd == tv.hwndFrozen, hti.ISubItem)
+}
+
+// lvCellAt returns the model row and the column of the cell at the specified
+// position, in client coordinates of list view hwnd, or -1, -1 if there is no
+// cell.
+func (tv *TableView) lvCellAt(hwnd win.HWND, x, y int32) (row, col int) {
+	var hti win.LVHITTESTINFO
+	hti.Pt = win.POINT{x, y}
+
+	if -1 == int32(win.SendMessage(hwnd, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&hti)))) ||
+		hti.Flags&win.LVHT_ONITEM == 0 {
+
+		return -1, -1
+	}
+
+	row = tv.ViewToModelIndex(int(hti.IItem))
+	col = tv.fromLVColIdx(hwnd == tv.hwndFrozen, hti.ISubItem)
+	if row == -1 || col == -1 {
+		return -1, -1
+	}
+
+	return row, col
+}
+
+// updateCellToolTip makes the tool tip of list view hwnd request its text
+// again, if the cursor moved to another cell. The list view itself only does
+// so when the cursor moves to another item.
+func (tv *TableView) updateCellToolTip(hwnd win.HWND, lp uintptr) {
+	row, col := tv.lvCellAt(hwnd, win.GET_X_LPARAM(lp), win.GET_Y_LPARAM(lp))
+	if row == tv.cellToolTipRow && col == tv.cellToolTipCol {
+		return
+	}
+
+	tv.cellToolTipRow, tv.cellToolTipCol = row, col
+
+	if hwndToolTip := win.HWND(win.SendMessage(hwnd, win.LVM_GETTOOLTIPS, 0, 0)); hwndToolTip != 0 {
+		win.SendMessage(hwndToolTip, win.TTM_UPDATE, 0, 0)
+	}
+}
+
+// hitTest runs a sub item hit test against the list view that contains the
+// specified position, in client coordinates of the *TableView.
+func (tv *TableView) hitTest(x, y int) (hwnd win.HWND, hti win.LVHITTESTINFO) {
+	pt := win.POINT{X: int32(x), Y: int32(y)}
+	win.ClientToScreen(tv.hWnd, &pt)
+
+	hwnd = tv.hwndNormal
+	if tv.hasFrozenColumn {
+		var rc win.RECT
+		if win.GetWindowRect(tv.hwndFrozen, &rc) && pt.X >= rc.Left && pt.X < rc.Right {
+			hwnd = tv.hwndFrozen
+		}
+	}
+
+	hti.Pt = pt
+	win.ScreenToClient(hwnd, &hti.Pt)
+
+	win.SendMessage(hwnd, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+	return
+}
+
+// ItemBounds returns the bounds of the item at the specified index, in client
+// coordinates of the *TableView.
+//
+// The bounds span the frozen and the normal columns. An empty Rectangle is
+// returned if index is out of range.
+func (tv *TableView) ItemBounds(index int) Rectangle {
+	if index < 0 || index >= tv.itemCount() {
+		return Rectangle{}
+	}
+
+	rc := win.RECT{Left: win.LVIR_BOUNDS}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_GETITEMRECT, uintptr(index), uintptr(unsafe.Pointer(&rc))) {
+		return Rectangle{}
+	}
+	rc = tv.rectToClient(tv.hwndNormal, rc)
+
+	if tv.hasFrozenColumn {
+		rcFrozen := win.RECT{Left: win.LVIR_BOUNDS}
+		if win.FALSE != win.SendMessage(tv.hwndFrozen, win.LVM_GETITEMRECT, uintptr(index), uintptr(unsafe.Pointer(&rcFrozen))) {
+			rcFrozen = tv.rectToClient(tv.hwndFrozen, rcFrozen)
+
+			rc.Left = int32(mini(int(rc.Left), int(rcFrozen.Left)))
+			rc.Right = int32(maxi(int(rc.Right), int(rcFrozen.Right)))
+		}
+	}
+
+	return rectangleFromRECT(rc)
+}
+
+// CellBounds returns the bounds of the cell at the specified row and column
+// index, in client coordinates of the *TableView.
+//
+// An empty Rectangle is returned if row or col is out of range or the column
+// is not visible.
+func (tv *TableView) CellBounds(row, col int) Rectangle {
+	if row < 0 || row >= tv.itemCount() || col < 0 || col >= tv.columns.Len() {
+		return Rectangle{}
+	}
+
+	tvc := tv.columns.items[col]
+	if !tvc.visible {
+		return Rectangle{}
+	}
+
+	hwnd := tv.hwndNormal
+	if tvc.frozen {
+		hwnd = tv.hwndFrozen
+	}
+
+	subItem := tvc.indexInListView()
+
+	rc := win.RECT{Top: subItem, Left: win.LVIR_BOUNDS}
+	if win.FALSE == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) {
+		return Rectangle{}
+	}
+
+	if subItem == 0 {
+		// For the first column, LVIR_BOUNDS returns the bounds of the whole
+		// item.
+		rc.Right = rc.Left + int32(tvc.Width())
+	}
+
+	return rectangleFromRECT(tv.rectToClient(hwnd, rc))
+}
+
+// ColumnClicked returns the event that is published after a column header was
+// clicked with the left mouse button.
+//
+// It is published for all columns, whether they are sortable or not. If the
+// model is sortable by the column, it is published after sorting.
+func (tv *TableView) ColumnClicked() *IntEvent {
+	return tv.columnClickedPublisher.Event()
+}
+
+// ColumnHeaderClicked returns the event that is published after a column
+// header was clicked with the left or right mouse button, along with the
+// modifier keys that were pressed.
+//
+// Like ColumnClicked, a left click is published after sorting, if the model is
+// sortable by the column.
+func (tv *TableView) ColumnHeaderClicked() *ColumnHeaderClickEvent {
+	return tv.columnHeaderClickedPublisher.Event()
+}
+
+// CellClicked returns the event that is published after the user clicked a
+// cell with the left mouse button.
+//
+// The handler receives the index of the item and of the column. Unlike
+// ItemActivated, it is published for single clicks and tells which cell was
+// clicked, e.g. to handle clickable icons in an action column.
+func (tv *TableView) CellClicked() *CellEvent {
+	return tv.cellClickedPublisher.Event()
+}
+
+// ItemCheckedChanged returns the event that is published after the user
+// toggled the check box of an item.
+//
+// The handler receives the index of the item.
+func (tv *TableView) ItemCheckedChanged() *IntEvent {
+	return tv.itemCheckedChangedPublisher.Event()
+}
+
+// ItemContextMenu returns the event that is published when the user requests
+// a context menu for the *TableView.
+//
+// The handler receives the row and column of the cell that was clicked, or -1
+// for clicks outside of any item or cell, and the position in screen
+// coordinates where a context menu should be shown. If the context menu was
+// requested using the keyboard, the row is the current index and the column
+// is -1.
+func (tv *TableView) ItemContextMenu() *ItemContextMenuEvent {
+	return tv.itemContextMenuPublisher.Event()
+}
+
+// ColumnWidthChanged returns the event that is published after the width of a
+// visible column changed, either by the user or programmatically.
+//
+// The handler receives the index of the column.
+func (tv *TableView) ColumnWidthChanged() *IntEvent {
+	return tv.columnWidthChangedPublisher.Event()
+}
+
+// ItemHovered returns the event that is published when the mouse cursor moved
+// to another item.
+//
+// The handler receives the index of the item under the cursor, or -1 if the
+// cursor left the items.
+func (tv *TableView) ItemHovered() *IntEvent {
+	return tv.itemHoveredPublisher.Event()
+}
+
+// HotIndex returns the index of the item under the mouse cursor, or -1 if the
+// cursor is not over an item.
+//
+// The value changes right before ItemHovered is published, so handlers of that
+// event, e.g. ones that display a button on the hovered item, can query it as
+// well.
+func (tv *TableView) HotIndex() int {
+	return tv.hoveredIndex
+}
+
+func (tv *TableView) updateHoveredIndex(hwnd win.HWND, msg uint32, lp uintptr) {
+	index := -1
+
+	if msg == win.WM_MOUSEMOVE {
+		var hti win.LVHITTESTINFO
+		hti.Pt = win.POINT{win.GET_X_LPARAM(lp), win.GET_Y_LPARAM(lp)}
+		win.SendMessage(hwnd, win.LVM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+		if hti.Flags&win.LVHT_ONITEM != 0 {
+			index = int(hti.IItem)
+		}
+	}
+
+	if index == tv.pendingHoveredIndex {
+		return
+	}
+
+	if tv.pendingHoveredIndex == -1 {
+		// Make sure we learn when the cursor leaves the list view.
+		tme := win.TRACKMOUSEEVENT{DwFlags: win.TME_LEAVE, HwndTrack: hwnd}
+		tme.CbSize = uint32(unsafe.Sizeof(tme))
+		win.TrackMouseEvent(&tme)
+	}
+
+	tv.pendingHoveredIndex = index
+
+	// Moving the cursor quickly across many items, or from one list view to
+	// the other, publishes only once.
+	if 0 == win.SetTimer(tv.hWnd, tableViewItemHoveredTimerId, 50, 0) {
+		lastError("SetTimer")
+	}
+}
+
+// VisibleRangeChanged returns the event that is published after the range of
+// visible items changed, e.g. because the *TableView was scrolled or resized.
+//
+// The handler receives the index of the top item and the number of visible
+// items.
+func (tv *TableView) VisibleRangeChanged() *VisibleRangeEvent {
+	return tv.visibleRangeChangedPublisher.Event()
+}
+
+func (tv *TableView) scheduleVisibleRangeChanged() {
+	if 0 == win.SetTimer(tv.hWnd, tableViewVisibleRangeChangedTimerId, 50, 0) {
+		lastError("SetTimer")
+	}
+}
+
+func (tv *TableView) publishVisibleRangeChangedIfNeeded() {
+	top := tv.TopIndex()
+	count := mini(tv.RowsPerPage(), tv.itemCount()-top)
+	if count < 0 {
+		count = 0
+	}
+
+	if top == tv.visibleTop && count == tv.visibleCount {
+		return
+	}
+
+	tv.visibleTop, tv.visibleCount = top, count
+
+	tv.visibleRangeChangedPublisher.Publish(top, count)
+}
+
+// ItemActivated returns the event that is published after an item was
+// activated.
+//
+// An item is activated when it is double clicked or the enter key is pressed
+// when the item is selected.
+func (tv *TableView) ItemActivated() *Event {
+	return tv.itemActivatedPublisher.Event()
+}
+
+// EnsureItemVisible scrolls the item at index into view, without changing the
+// current item or the selection.
+func (tv *TableView) EnsureItemVisible(index int) error {
+	if err := tv.ensureItemVisible(tv.hwndFrozen, index); err != nil {
+		return err
+	}
+
+	if err := tv.ensureItemVisible(tv.hwndNormal, index); err != nil {
+		return err
+	}
+
+	tv.publishVisibleRangeChangedIfNeeded()
+
+	return nil
+}
+
+func (tv *TableView) ensureItemVisible(hwnd win.HWND, index int) error {
+	if win.FALSE == win.SendMessage(hwnd, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
+		return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	}
+
+	top := int(win.SendMessage(hwnd, win.LVM_GETTOPINDEX, 0, 0))
+	count := int(win.SendMessage(hwnd, win.LVM_GETCOUNTPERPAGE, 0, 0))
+	if index >= top && index < top+count {
+		return nil
+	}
+
+	// Windows bug? Sometimes a second LVM_ENSUREVISIBLE is required.
+	if win.FALSE == win.SendMessage(hwnd, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
+		return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	}
+
+	return nil
+}
+
+// TopIndex returns the index of the topmost visible item.
+func (tv *TableView) TopIndex() int {
+	return int(win.SendMessage(tv.hwndNormal, win.LVM_GETTOPINDEX, 0, 0))
+}
+
+// ScrollToTop scrolls the *TableView so that the item at index becomes the top
+// visible item, as far as the number of items permits.
+func (tv *TableView) ScrollToTop(index int) error {
+	if index < 0 || index >= tv.itemCount() {
+		return newError("index out of range")
+	}
+
+	return tv.scrollByRows(index - tv.TopIndex())
+}
+
+// ScrollByPages scrolls the *TableView down by n pages, or up if n is
+// negative.
+func (tv *TableView) ScrollByPages(n int) error {
+	return tv.scrollByRows(n * maxi(1, tv.RowsPerPage()))
+}
+
+func (tv *TableView) scrollByRows(rows int) error {
+	if rows == 0 || tv.itemCount() == 0 {
+		return nil
+	}
+
+	tv.endCellEdit(true)
+
+	var rc win.RECT
+	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_GETITEMRECT, 0, uintptr(unsafe.Pointer(&rc))) {
+		return newError("SendMessage(LVM_GETITEMRECT)")
+	}
+
+	dy := uintptr(rows * int(rc.Bottom-rc.Top))
+
+	// Keep the LVN_BEGINSCROLL handler from scrolling the other list view a
+	// second time.
+	tv.scrolling = true
+	defer func() {
+		tv.scrolling = false
+	}()
+
+	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SCROLL, 0, dy) {
+		return newError("SendMessage(LVM_SCROLL)")
+	}
+	if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_SCROLL, 0, dy) {
+		return newError("SendMessage(LVM_SCROLL)")
+	}
+
+	tv.scheduleVisibleRangeChanged()
+
+	return nil
+}
+
+// SetSelectionChanging sets a function that is called before the user selects
+// another item in single selection mode.
+//
+// If the function returns false, the previous item stays selected. Pass nil
+// to allow all selection changes.
+func (tv *TableView) SetSelectionChanging(f func(oldIndex, newIndex int) bool) {
+	tv.selectionChanging = f
+}
+
+// selectionChangeAllowed returns if the user may make the item at index the
+// current item, according to the function set with SetSelectionChanging.
+func (tv *TableView) selectionChangeAllowed(index int) bool {
+	if tv.selectionChanging == nil || tv.MultiSelection() || index == tv.currentIndex {
+		return true
+	}
+
+	return tv.selectionChanging(tv.currentIndex, index)
+}
+
+// SetDeleteKeyHandler sets a function that is called when the user presses
+// the Delete key while items are selected.
+//
+// The function receives the indexes of the selected items. The *TableView
+// does not change the model by itself, it is up to the function to remove the
+// items, if appropriate. Pass nil to ignore the Delete key.
+func (tv *TableView) SetDeleteKeyHandler(f func(indexes []int)) {
+	tv.deleteKeyHandler = f
+}
+
+// CurrentIndex returns the index of the current item, or -1 if there is no
+// current item.
+func (tv *TableView) CurrentIndex() int {
+	return tv.currentIndex
+}
+
+// SetCurrentIndex sets the index of the current item.
+//
+// Call this with a value of -1 to have no current item. If the model
+// implements RowSelectabilityProvider, the item must be selectable.
+func (tv *TableView) SetCurrentIndex(index int) error {
+	if tv.inSetCurrentIndex {
+		return nil
+	}
+	if index > -1 && !tv.rowSelectable(index) {
+		return newError("item is not selectable")
+	}
+	tv.inSetCurrentIndex = true
+	defer func() {
+		tv.inSetCurrentIndex = false
+	}()
+
+	var lvi win.LVITEM
+
+	lvi.StateMask = win.LVIS_FOCUSED | win.LVIS_SELECTED
+
+	if tv.MultiSelection() {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, ^uintptr(0), uintptr(unsafe.Pointer(&lvi))) {
+			return newError("SendMessage(LVM_SETITEMSTATE)")
+		}
+		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, ^uintptr(0), uintptr(unsafe.Pointer(&lvi))) {
+			return newError("SendMessage(LVM_SETITEMSTATE)")
+		}
+	}
+
+	if index > -1 {
+		lvi.State = win.LVIS_FOCUSED | win.LVIS_SELECTED
+	}
+
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi))) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi))) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+
+	if index != -1 {
+		if err := tv.EnsureItemVisible(index); err != nil {
+			return err
+		}
+	}
+
+	tv.currentIndex = index
+
+	tv.invalidatePinnedRows()
+
+	if index == -1 || tv.itemStateChangedEventDelay == 0 {
+		tv.currentIndexChangedPublisher.Publish()
+	}
+
+	if tv.MultiSelection() {
+		tv.updateSelectedIndexes()
+	}
+
+	tv.rememberSelectedItemIDs()
+
+	return nil
+}
+
+// jumpToItem makes the item at index current. If extend is true, the items
+// from the selection mark of hwnd to index are selected.
+func (tv *TableView) jumpToItem(hwnd win.HWND, index int, extend bool) {
+	if !extend {
+		if !tv.selectionChangeAllowed(index) {
+			return
+		}
+
+		tv.SetCurrentIndex(index)
+
+		win.SendMessage(tv.hwndFrozen, win.LVM_SETSELECTIONMARK, 0, uintptr(index))
+		win.SendMessage(tv.hwndNormal, win.LVM_SETSELECTIONMARK, 0, uintptr(index))
+		return
+	}
+
+	anchor := int(int32(win.SendMessage(hwnd, win.LVM_GETSELECTIONMARK, 0, 0)))
+	if anchor < 0 {
+		anchor = tv.currentIndex
+	}
+	if anchor < 0 {
+		anchor = index
+	}
+
+	// The item at index is set last, so it ends up with the focus.
+	step := 1
+	if index < anchor {
+		step = -1
+	}
+
+	indexes := make([]int, 0, 1+(index-anchor)*step)
+	for i := anchor; i != index+step; i += step {
+		if tv.rowSelectable(i) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	oldIndex := tv.currentIndex
+
+	tv.selectIndexes(indexes)
+
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETSELECTIONMARK, 0, uintptr(anchor))
+	win.SendMessage(tv.hwndNormal, win.LVM_SETSELECTIONMARK, 0, uintptr(anchor))
+
+	tv.currentIndex = index
+	if index != oldIndex {
+		tv.currentIndexChangedPublisher.Publish()
+	}
+
+	tv.EnsureItemVisible(index)
+}
+
+// moveFocusedItem moves the focus rectangle of the list views as navigation
+// key vk would, but leaves the selection alone.
+func (tv *TableView) moveFocusedItem(hwnd win.HWND, vk uintptr) {
+	count := tv.itemCount()
+	if count == 0 {
+		return
+	}
+
+	index := int(int32(win.SendMessage(hwnd, win.LVM_GETNEXTITEM, ^uintptr(0), win.LVNI_FOCUSED)))
+
+	switch vk {
+	case win.VK_UP:
+		index--
+
+	case win.VK_DOWN:
+		index++
+
+	case win.VK_PRIOR:
+		index -= maxi(1, tv.RowsPerPage()-1)
+
+	case win.VK_NEXT:
+		index += maxi(1, tv.RowsPerPage()-1)
+
+	case win.VK_HOME:
+		index = 0
+
+	case win.VK_END:
+		index = count - 1
+	}
+
+	index = maxi(0, mini(count-1, index))
+
+	lvi := win.LVITEM{State: win.LVIS_FOCUSED, StateMask: win.LVIS_FOCUSED}
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+	win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+
+	tv.EnsureItemVisible(index)
+}
+
+// CurrentIndexChanged is the event that is published after CurrentIndex has
+// changed.
+func (tv *TableView) CurrentIndexChanged() *Event {
+	return tv.currentIndexChangedPublisher.Event()
+}
+
+// MultiSelection returns whether multiple items can be selected at once.
+//
+// By default only a single item can be selected at once.
+func (tv *TableView) MultiSelection() bool {
+	style := uint(win.GetWindowLong(tv.hwndNormal, win.GWL_STYLE))
+	if style == 0 {
+		lastError("GetWindowLong")
+		return false
+	}
+
+	return style&win.LVS_SINGLESEL == 0
+}
+
+// SetMultiSelection sets whether multiple items can be selected at once.
+func (tv *TableView) SetMultiSelection(multiSel bool) error {
+	if err := ensureWindowLongBits(tv.hwndFrozen, win.GWL_STYLE, win.LVS_SINGLESEL, !multiSel); err != nil {
+		return err
+	}
+
+	return ensureWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.LVS_SINGLESEL, !multiSel)
+}
+
+// SelectedIndexes returns the indexes of the currently selected items.
+func (tv *TableView) SelectedIndexes() []int {
+	indexes := make([]int, len(tv.selectedIndexes))
+
+	for i, j := range tv.selectedIndexes {
+		indexes[i] = j
+	}
+
+	return indexes
+}
+
+// PreserveSelectionOnReset returns if the *TableView restores its selection
+// after the model published RowsReset.
+func (tv *TableView) PreserveSelectionOnReset() bool {
+	return tv.preserveSelectionOnReset
+}
+
+// SetPreserveSelectionOnReset sets if the *TableView restores its selection
+// after the model published RowsReset.
+//
+// This requires the model to implement ItemIDProvider, so that items can be
+// found again by their IDs. Otherwise, or if disabled, the selection is
+// cleared on reset.
+func (tv *TableView) SetPreserveSelectionOnReset(preserve bool) {
+	tv.preserveSelectionOnReset = preserve
+
+	tv.rememberSelectedItemIDs()
+}
+
+func (tv *TableView) rememberSelectedItemIDs() {
+	tv.currentItemID, tv.selectedItemIDs = nil, nil
+
+	if !tv.preserveSelectionOnReset || tv.itemIDProvider == nil || tv.model == nil {
+		return
+	}
+
+	count := tv.model.RowCount()
+
+	if row := tv.ViewToModelIndex(tv.currentIndex); row > -1 && row < count {
+		tv.currentItemID = tv.itemIDProvider.ItemID(row)
+	}
+
+	if !tv.MultiSelection() {
+		return
+	}
+
+	for _, index := range tv.selectedIndexes {
+		if row := tv.ViewToModelIndex(index); row > -1 && row < count {
+			tv.selectedItemIDs = append(tv.selectedItemIDs, tv.itemIDProvider.ItemID(row))
+		}
+	}
+}
+
+func (tv *TableView) restoreSelectionByItemIDs(currentID interface{}, selectedIDs []interface{}) {
+	id2Selected := make(map[interface{}]bool, len(selectedIDs))
+	for _, id := range selectedIDs {
+		id2Selected[id] = true
+	}
+
+	current := -1
+	var indexes []int
+
+	count := tv.model.RowCount()
+	if currentID == nil && len(id2Selected) == 0 {
+		count = 0
+	}
+
+	for row := 0; row < count; row++ {
+		index := tv.ModelToViewIndex(row)
+		if index == -1 {
+			continue
+		}
+
+		id := tv.itemIDProvider.ItemID(row)
+
+		if currentID != nil && id == currentID {
+			current = index
+		}
+		if id2Selected[id] {
+			indexes = append(indexes, index)
+		}
+	}
+
+	tv.setCurrentIndexOrNone(current)
+
+	if tv.MultiSelection() && len(indexes) > 0 {
+		tv.SetSelectedIndexes(indexes)
+	}
+}
+
+// SelectRowsFunc selects the items for which pred returns true. pred is called
+// with the model row index of each displayed item, so it may use it with the
+// model directly, even if a row filter is set.
+//
+// In single selection mode, only the first matching item is selected.
+func (tv *TableView) SelectRowsFunc(pred func(row int) bool) error {
+	if pred == nil {
+		return newError("pred must not be nil")
+	}
+
+	count := tv.itemCount()
+
+	if !tv.MultiSelection() {
+		for index := 0; index < count; index++ {
+			if pred(tv.ViewToModelIndex(index)) {
+				return tv.SetCurrentIndex(index)
+			}
+		}
+
+		return tv.SetCurrentIndex(-1)
+	}
+
+	var indexes []int
+	for index := 0; index < count; index++ {
+		if pred(tv.ViewToModelIndex(index)) {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return tv.selectIndexes(indexes)
+}
+
+// selectIndexes is like SetSelectedIndexes, but keeps the list views from
+// making each selected item current, one by one.
+func (tv *TableView) selectIndexes(indexes []int) error {
+	tv.inSetCurrentIndex = true
+	defer func() {
+		tv.inSetCurrentIndex = false
+	}()
+
+	return tv.SetSelectedIndexes(indexes)
+}
+
+// SelectAll selects all items.
+//
+// This requires the *TableView to be in multi selection mode.
+func (tv *TableView) SelectAll() error {
+	if !tv.MultiSelection() {
+		return newError("SelectAll requires multi selection")
+	}
+
+	tv.inSetSelectedIndexes = true
+	defer func() {
+		tv.inSetSelectedIndexes = false
+		tv.updateSelectedIndexes()
+	}()
+
+	lvi := &win.LVITEM{State: win.LVIS_SELECTED, StateMask: win.LVIS_SELECTED}
+	lp := uintptr(unsafe.Pointer(lvi))
+
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+
+	return nil
+}
+
+// IsRowSelected returns if the item at index is selected.
+func (tv *TableView) IsRowSelected(index int) bool {
+	if !tv.MultiSelection() {
+		return win.SendMessage(tv.hwndNormal, win.LVM_GETITEMSTATE, uintptr(index), win.LVIS_SELECTED)&win.LVIS_SELECTED != 0
+	}
+
+	for _, i := range tv.selectedIndexes {
+		if i == index {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetSelectedIndexes sets the indexes of the currently selected items.
+func (tv *TableView) SetSelectedIndexes(indexes []int) error {
+	tv.inSetSelectedIndexes = true
+	defer func() {
+		tv.inSetSelectedIndexes = false
+		tv.publishSelectedIndexesChanged()
+	}()
+
+	lvi := &win.LVITEM{StateMask: win.LVIS_FOCUSED | win.LVIS_SELECTED}
+	lp := uintptr(unsafe.Pointer(lvi))
+
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
+		return newError("SendMessage(LVM_SETITEMSTATE)")
+	}
+
+	lvi.State = win.LVIS_FOCUSED | win.LVIS_SELECTED
+	for _, i := range indexes {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(i), lp) {
+			return newError("SendMessage(LVM_SETITEMSTATE)")
+		}
+		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(i), lp) {
+			return newError("SendMessage(LVM_SETITEMSTATE)")
+		}
+	}
+
+	idxs := make([]int, len(indexes))
+
+	for i, j := range indexes {
+		idxs[i] = j
+	}
+
+	tv.selectedIndexes = idxs
+
+	return nil
+}
+
+func (tv *TableView) updateSelectedIndexes() {
+	count := int(win.SendMessage(tv.hwndNormal, win.LVM_GETSELECTEDCOUNT, 0, 0))
+	indexes := make([]int, 0, count)
+
+	j := -1
+	for i := 0; i < count; i++ {
+		j = int(win.SendMessage(tv.hwndNormal, win.LVM_GETNEXTITEM, uintptr(j), win.LVNI_SELECTED))
+
+		if !tv.rowSelectable(j) {
+			tv.deselectItem(j)
+			continue
+		}
+
+		indexes = append(indexes, j)
+	}
+
+	changed := len(indexes) != len(tv.selectedIndexes)
+	if !changed {
+		for i := 0; i < len(indexes); i++ {
+			if indexes[i] != tv.selectedIndexes[i] {
+				changed = true
+				break
+			}
+		}
+	}
+
+	if changed {
+		tv.selectedIndexes = indexes
+		tv.publishSelectedIndexesChanged()
+	}
+}
+
+// rowSelectable returns if the item at index can be selected, according to the
+// RowSelectabilityProvider of the model.
+func (tv *TableView) rowSelectable(index int) bool {
+	if tv.rowSelectability == nil {
+		return true
+	}
+
+	row := tv.ViewToModelIndex(index)
+
+	return row > -1 && tv.rowSelectability.Selectable(row)
+}
+
+// nextSelectableIndex returns the first selectable item, starting at index
+// from and moving by step, or -1 if there is none.
+func (tv *TableView) nextSelectableIndex(from, step int) int {
+	for i, count := from, tv.itemCount(); i >= 0 && i < count; i += step {
+		if tv.rowSelectable(i) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// setCurrentIndexOrNone makes the item at index the current item, or leaves
+// the *TableView without a current item, if the item is not selectable.
+func (tv *TableView) setCurrentIndexOrNone(index int) error {
+	if index > -1 && !tv.rowSelectable(index) {
+		index = -1
+	}
+
+	return tv.SetCurrentIndex(index)
+}
+
+func (tv *TableView) deselectItem(index int) {
+	lvi := win.LVITEM{StateMask: win.LVIS_SELECTED}
+
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+	win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi)))
+}
+
+// ItemStateChangedEventDelay returns the delay in milliseconds, between the
+// moment the state of an item in the *TableView changes and the moment the
+// associated event is published.
+//
+// By default there is no delay.
+func (tv *TableView) ItemStateChangedEventDelay() int {
+	return tv.itemStateChangedEventDelay
+}
+
+// SetItemStateChangedEventDelay sets the delay in milliseconds, between the
+// moment the state of an item in the *TableView changes and the moment the
+// associated event is published.
+//
+// An example where this may be useful is a master-details scenario. If the
+// master TableView is configured to delay the event, you can avoid pointless
+// updates of the details TableView, if the user uses arrow keys to rapidly
+// navigate the master view.
+func (tv *TableView) SetItemStateChangedEventDelay(delay int) {
+	tv.itemStateChangedEventDelay = delay
+}
+
+// SelectedIndexesChanged returns the event that is published when the list of
+// selected item indexes changed.
+func (tv *TableView) SelectedIndexesChanged() *Event {
+	return tv.selectedIndexesChangedPublisher.Event()
+}
+
+// SelectionDeltaChanged returns the event that is published right before
+// SelectedIndexesChanged, if items were selected or deselected.
+//
+// The handler receives the indexes of the newly selected and of the newly
+// deselected items, each in ascending order.
+func (tv *TableView) SelectionDeltaChanged() *SelectionDeltaEvent {
+	return tv.selectionDeltaChangedPublisher.Event()
+}
+
+func (tv *TableView) publishSelectionDeltaChanged() {
+	oldIndexes := tv.deltaBaseSelectedIndexes
+	newIndexes := append([]int(nil), tv.selectedIndexes...)
+	sort.Ints(newIndexes)
+
+	tv.deltaBaseSelectedIndexes = newIndexes
+
+	var selected, deselected []int
+	i, j := 0, 0
+	for i < len(oldIndexes) || j < len(newIndexes) {
+		switch {
+		case j == len(newIndexes) || i < len(oldIndexes) && oldIndexes[i] < newIndexes[j]:
+			deselected = append(deselected, oldIndexes[i])
+			i++
+
+		case i == len(oldIndexes) || newIndexes[j] < oldIndexes[i]:
+			selected = append(selected, newIndexes[j])
+			j++
+
+		default:
+			i++
+			j++
+		}
+	}
+
+	if len(selected) > 0 || len(deselected) > 0 {
+		tv.selectionDeltaChangedPublisher.Publish(selected, deselected)
+	}
+}
+
+func (tv *TableView) publishSelectedIndexesChanged() {
+	tv.rememberSelectedItemIDs()
+
+	if tv.itemStateChangedEventDelay > 0 {
+		if 0 == win.SetTimer(
+			tv.hWnd,
+			tableViewSelectedIndexesChangedTimerId,
+			uint32(tv.itemStateChangedEventDelay),
+			0) {
+
+			lastError("SetTimer")
+		}
+	} else {
+		tv.publishSelectionDeltaChanged()
+		tv.selectedIndexesChangedPublisher.Publish()
+	}
+}
+
+// LastColumnStretched returns if the last column should take up all remaining
+// horizontal space of the *TableView.
+func (tv *TableView) LastColumnStretched() bool {
+	return tv.lastColumnStretched
+}
+
+// SetLastColumnStretched sets if the last column should take up all remaining
+// horizontal space of the *TableView.
+//
+// The effect of setting this is persistent.
+func (tv *TableView) SetLastColumnStretched(value bool) error {
+	if value {
+		if err := tv.StretchLastColumn(); err != nil {
+			return err
+		}
+	}
+
+	tv.lastColumnStretched = value
+
+	return nil
+}
+
+// StretchLastColumn makes the last column take up all remaining horizontal
+// space of the *TableView.
+//
+// The effect of this is not persistent.
+func (tv *TableView) StretchLastColumn() error {
+	colCount := tv.visibleColumnCount()
+	if colCount == 0 {
+		return nil
+	}
+
+	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETCOLUMNWIDTH, uintptr(colCount-1), win.LVSCW_AUTOSIZE_USEHEADER) {
+		return newError("LVM_SETCOLUMNWIDTH failed")
+	}
+
+	return nil
+}
+
+// ColumnWidthFraction returns the share of the available width, that the
+// column at index col receives, or 0 if the column has a fixed width.
+func (tv *TableView) ColumnWidthFraction(col int) float64 {
+	if col < 0 || col >= tv.columns.Len() {
+		return 0
+	}
+
+	return tv.columns.items[col].widthFraction
+}
+
+// SetColumnWidthFraction sets the share of the available width, that the
+// column at index col receives whenever the *TableView is resized.
+//
+// The available width is the client width minus the widths of all visible
+// columns without a fraction. It is distributed among the columns with a
+// fraction in proportion to their fractions, so the fractions of e.g. two
+// columns may be 0.25 and 0.75 or 1 and 3. Pass 0 to give the column a fixed
+// width again.
+func (tv *TableView) SetColumnWidthFraction(col int, fraction float64) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+	if fraction < 0 {
+		return newError("fraction must be >= 0")
+	}
+
+	tv.columns.items[col].widthFraction = fraction
+
+	tv.applyColumnWidthFractions()
+
+	return nil
+}
+
+func (tv *TableView) applyColumnWidthFractions() {
+	cols := tv.VisibleColumnsInDisplayOrder()
+
+	var fractionCols []*TableViewColumn
+	var sum float64
+	var fixedWidth int
+
+	for i, tvc := range cols {
+		switch {
+		case tvc.widthFraction > 0:
+			fractionCols = append(fractionCols, tvc)
+			sum += tvc.widthFraction
+
+		case tv.lastColumnStretched && i == len(cols)-1:
+			// The stretched column takes what is left, but should at
+			// least fit its header.
+			fixedWidth += tv.calculateTextSizeImpl(tvc.TitleEffective()).Width + tableViewCellPadding
+
+		default:
+			fixedWidth += tvc.Width()
 		}
+	}
 
-		if win.SendMessage(headerHwnd, win.HDM_SETITEM, iPtr, itemPtr) == 0 {
-			return newError("SendMessage(HDM_SETITEM)")
+	if sum == 0 {
+		return
+	}
+
+	available := tv.ClientBounds().Width
+	if hasWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.WS_VSCROLL) {
+		available -= int(win.GetSystemMetrics(win.SM_CXVSCROLL))
+	}
+	available = maxi(0, available-fixedWidth)
+
+	remaining := available
+	for i, tvc := range fractionCols {
+		width := remaining
+		if i < len(fractionCols)-1 {
+			width = int(float64(available) * tvc.widthFraction / sum)
+		}
+		remaining -= width
+
+		tvc.SetWidth(tvc.constrainedWidth(width))
+	}
+
+	tv.updateLVSizes()
+}
+
+// SizeColumnsToContent sets the widths of all visible columns, so their
+// content fits.
+//
+// As the list views of a *TableView do not measure virtual items, the widths
+// are calculated from the text of the currently visible rows. Columns are
+// never made narrower than their header.
+func (tv *TableView) SizeColumnsToContent() error {
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible {
+			continue
+		}
+
+		if err := tv.sizeColumnToContent(tvc); err != nil {
+			return err
 		}
 	}
 
+	tv.updateLVSizes()
+
 	return nil
 }
 
-// ColumnClicked returns the event that is published after a column header was
-// clicked.
-func (tv *TableView) ColumnClicked() *IntEvent {
-	return tv.columnClickedPublisher.Event()
+func (tv *TableView) sizeColumnToContent(tvc *TableViewColumn) error {
+	var hwnd win.HWND
+	if tvc.frozen {
+		hwnd = tv.hwndFrozen
+	} else {
+		hwnd = tv.hwndNormal
+	}
+
+	idx := tvc.indexInListView()
+
+	width, ok := tv.cellsWidth(tvc)
+	if !ok {
+		if 0 == win.SendMessage(hwnd, win.LVM_SETCOLUMNWIDTH, uintptr(idx), win.LVSCW_AUTOSIZE_USEHEADER) {
+			return newError("LVM_SETCOLUMNWIDTH failed")
+		}
+
+		return nil
+	}
+
+	width = maxi(width, tv.headerWidth(tvc))
+
+	if 0 == win.SendMessage(hwnd, win.LVM_SETCOLUMNWIDTH, uintptr(idx), uintptr(width)) {
+		return newError("LVM_SETCOLUMNWIDTH failed")
+	}
+
+	return nil
 }
 
-// ItemActivated returns the event that is published after an item was
-// activated.
-//
-// An item is activated when it is double clicked or the enter key is pressed
-// when the item is selected.
-func (tv *TableView) ItemActivated() *Event {
-	return tv.itemActivatedPublisher.Event()
+// headerWidth returns the width required to display the header of tvc.
+func (tv *TableView) headerWidth(tvc *TableViewColumn) int {
+	width := tv.calculateTextSizeImpl(tvc.TitleEffective()).Width + tableViewCellPadding
+	if tvc.headerCheckBox {
+		width += int(win.GetSystemMetrics(win.SM_CXMENUCHECK))
+	}
+
+	return width
 }
 
-// CurrentIndex returns the index of the current item, or -1 if there is no
-// current item.
-func (tv *TableView) CurrentIndex() int {
-	return tv.currentIndex
+// cellsWidth returns the width required to display the visible cells of tvc,
+// or false if no items are visible.
+func (tv *TableView) cellsWidth(tvc *TableViewColumn) (int, bool) {
+	first := tv.TopIndex()
+	count := tv.itemCount() - first
+	count = mini(count, int(win.SendMessage(tv.hwndNormal, win.LVM_GETCOUNTPERPAGE, 0, 0))+1)
+
+	if count <= 0 {
+		return 0, false
+	}
+
+	col := tv.columns.Index(tvc)
+
+	var width int
+	for row := first; row < first+count; row++ {
+		width = maxi(width, tv.calculateTextSizeImpl(tv.CellText(row, col)).Width)
+	}
+
+	width += tableViewCellPadding
+
+	if tvc.visible && tvc.indexInListView() == 0 && tvc.frozen == tv.hasFrozenColumn {
+		// This column hosts item images and check boxes.
+		iconWidth := int(win.GetSystemMetrics(win.SM_CXSMICON))
+
+		if tv.hIml != 0 {
+			width += iconWidth
+		}
+		if tv.CheckBoxes() {
+			width += iconWidth
+		}
+	}
+
+	return width, true
 }
 
-// SetCurrentIndex sets the index of the current item.
+// PreferredColumnWidths returns the widths in pixels, that the visible columns
+// require to display their header and the currently visible cells without
+// truncation, in the order of Columns.
+func (tv *TableView) PreferredColumnWidths() []int {
+	var widths []int
+
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible {
+			continue
+		}
+
+		width := tv.headerWidth(tvc)
+
+		if cellsWidth, ok := tv.cellsWidth(tvc); ok {
+			width = maxi(width, cellsWidth)
+		}
+
+		widths = append(widths, tvc.constrainedWidth(width))
+	}
+
+	return widths
+}
+
+// SetRightToLeftReading sets whether the reading order of the *TableView is
+// from right to left.
 //
-// Call this with a value of -1 to have no current item.
-func (tv *TableView) SetCurrentIndex(index int) error {
-	if tv.inSetCurrentIndex {
+// This also mirrors the layout of the list views, so the first column is
+// displayed on the right and frozen columns stay on the right side.
+func (tv *TableView) SetRightToLeftReading(rtl bool) error {
+	if err := tv.WidgetBase.SetRightToLeftReading(rtl); err != nil {
+		return err
+	}
+
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		hwndHeader := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+
+		for _, h := range [2]win.HWND{hwnd, hwndHeader} {
+			if err := ensureWindowLongBits(h, win.GWL_EXSTYLE, win.WS_EX_LAYOUTRTL|win.WS_EX_RTLREADING, rtl); err != nil {
+				return err
+			}
+		}
+	}
+
+	tv.updateLVSizes()
+
+	return tv.Invalidate()
+}
+
+// Persistent returns if the *TableView should persist its UI state, like column
+// widths. See *App.Settings for details.
+func (tv *TableView) Persistent() bool {
+	return tv.persistent
+}
+
+// SetPersistent sets if the *TableView should persist its UI state, like column
+// widths. See *App.Settings for details.
+func (tv *TableView) SetPersistent(value bool) {
+	tv.persistent = value
+}
+
+type tableViewState struct {
+	SortColumnName     string
+	SortOrder          SortOrder
+	SortColumnNames    []string    `json:",omitempty"`
+	SortOrders         []SortOrder `json:",omitempty"`
+	ColumnDisplayOrder []string    // Also indicates visibility
+	TopIndex           *int        `json:",omitempty"`
+	CurrentIndex       *int        `json:",omitempty"`
+	Columns            []tableViewColumnState
+}
+
+type tableViewColumnState struct {
+	Name          string
+	Title         string
+	Width         int
+	WidthFraction float64 `json:",omitempty"`
+	Frozen        bool
+	Visible       *bool `json:",omitempty"`
+}
+
+// SaveState writes the UI state of the *TableView to the settings.
+func (tv *TableView) SaveState() error {
+	if tv.columns.Len() == 0 {
 		return nil
 	}
-	tv.inSetCurrentIndex = true
-	defer func() {
-		tv.inSetCurrentIndex = false
-	}()
 
-	var lvi win.LVITEM
+	var tvs tableViewState
+
+	// The sorted column may have been removed since.
+	if tv.sortedColumnIndex >= 0 && tv.sortedColumnIndex < tv.columns.Len() {
+		tvs.SortColumnName = tv.columns.items[tv.sortedColumnIndex].name
+	}
+	tvs.SortOrder = tv.sortOrder
+
+	if ms, ok := tv.model.(MultiSorter); ok {
+		orders := ms.SortOrders()
+
+		for i, col := range ms.SortedColumns() {
+			if col < 0 || col >= tv.columns.Len() || i >= len(orders) {
+				continue
+			}
+
+			tvs.SortColumnNames = append(tvs.SortColumnNames, tv.columns.items[col].name)
+			tvs.SortOrders = append(tvs.SortOrders, orders[i])
+		}
+	}
+
+	tvs.Columns = make([]tableViewColumnState, tv.columns.Len())
+
+	for i, tvc := range tv.columns.items {
+		tvcs := &tvs.Columns[i]
+
+		tvcs.Name = tvc.name
+		tvcs.Title = tvc.titleOverride
+		tvcs.Width = tvc.Width()
+		tvcs.WidthFraction = tvc.widthFraction
+		tvcs.Frozen = tvc.Frozen()
+		visible := tvc.Visible()
+		tvcs.Visible = &visible
+	}
+
+	visibleCols := tv.VisibleColumnsInDisplayOrder()
+	if visibleCols == nil && tv.visibleColumnCount() > 0 {
+		return newError("LVM_GETCOLUMNORDERARRAY")
+	}
+
+	tvs.ColumnDisplayOrder = make([]string, len(visibleCols))
+	for i, tvc := range visibleCols {
+		tvs.ColumnDisplayOrder[i] = tvc.name
+	}
+
+	topIndex, currentIndex := tv.TopIndex(), tv.CurrentIndex()
+	tvs.TopIndex = &topIndex
+	tvs.CurrentIndex = &currentIndex
+
+	state, err := json.Marshal(tvs)
+	if err != nil {
+		return err
+	}
+
+	return tv.WriteState(string(state))
+}
+
+// RestoreState restores the UI state of the *TableView from the settings.
+func (tv *TableView) RestoreState() error {
+	state, err := tv.ReadState()
+	if err != nil {
+		return err
+	}
+
+	// Columns are usually configured completely before the state is first
+	// restored, so that is the layout ResetState goes back to.
+	if !tv.columnDefaultsSaved {
+		tv.columnDefaultsSaved = true
+
+		for _, tvc := range tv.columns.items {
+			tvc.saveDefaults()
+		}
+	}
+
+	if state == "" {
+		return nil
+	}
+
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	var tvs tableViewState
+
+	if err := json.Unmarshal(([]byte)(state), &tvs); err != nil {
+		return err
+	}
+
+	name2tvc := make(map[string]*TableViewColumn)
+
+	for _, tvc := range tv.columns.items {
+		name2tvc[tvc.name] = tvc
+	}
+
+	name2tvcs := make(map[string]*tableViewColumnState)
+
+	for i, tvcs := range tvs.Columns {
+		name2tvcs[tvcs.Name] = &tvs.Columns[i]
+
+		if tvc := name2tvc[tvcs.Name]; tvc != nil {
+			if err := tvc.SetTitleOverride(tvcs.Title); err != nil {
+				return err
+			}
+			if tvcs.WidthFraction > 0 {
+				tvc.widthFraction = tvcs.WidthFraction
+			} else if err := tvc.SetWidth(tvcs.Width); err != nil {
+				return err
+			}
+			var visible bool
+			if tvcs.Visible != nil {
+				visible = *tvcs.Visible
+			} else {
+				// State saved by older versions only lists visible columns
+				// in the display order.
+				for _, name := range tvs.ColumnDisplayOrder {
+					if name == tvc.name {
+						visible = tvc.visible
+						break
+					}
+				}
+			}
+			if err := tvc.SetVisible(visible); err != nil {
+				return err
+			}
+			if err := tvc.SetFrozen(tvcs.Frozen); err != nil {
+				return err
+			}
+		}
+	}
+
+	displayOrder := make([]*TableViewColumn, 0, len(tvs.ColumnDisplayOrder))
+	for _, name := range tvs.ColumnDisplayOrder {
+		if tvc, ok := name2tvc[name]; ok {
+			displayOrder = append(displayOrder, tvc)
+		}
+	}
+
+	if err := tv.setColumnDisplayOrder(displayOrder); err != nil {
+		return err
+	}
+
+	tv.applyColumnWidthFractions()
+
+	visibleCount := tv.visibleColumnCount()
+
+	for i, c := range tvs.Columns {
+		if c.Name == tvs.SortColumnName && i < visibleCount {
+			tv.sortedColumnIndex = i
+			tv.sortOrder = tvs.SortOrder
+			break
+		}
+	}
+
+	if ms, ok := tv.model.(MultiSorter); ok && len(tvs.SortColumnNames) > 1 {
+		var cols []int
+		var orders []SortOrder
+
+		for i, name := range tvs.SortColumnNames {
+			if tvc, ok := name2tvc[name]; ok && i < len(tvs.SortOrders) {
+				if col := tv.columns.Index(tvc); ms.ColumnSortable(col) {
+					cols = append(cols, col)
+					orders = append(orders, tvs.SortOrders[i])
+				}
+			}
+		}
+
+		if len(cols) > 0 {
+			tv.sortedColumnIndex = cols[0]
+			tv.sortOrder = orders[0]
+
+			if err := ms.SortBy(cols, orders); err != nil {
+				return err
+			}
+
+			return tv.restoreItemPosition(&tvs)
+		}
+	}
+
+	if sorter, ok := tv.model.(Sorter); ok {
+		if !sorter.ColumnSortable(tv.sortedColumnIndex) {
+			for i := range tvs.Columns {
+				if sorter.ColumnSortable(i) {
+					tv.sortedColumnIndex = i
+				}
+			}
+		}
+
+		sorter.Sort(tv.sortedColumnIndex, tvs.SortOrder)
+	}
 
-	lvi.StateMask = win.LVIS_FOCUSED | win.LVIS_SELECTED
+	return tv.restoreItemPosition(&tvs)
+}
 
-	if tv.MultiSelection() {
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, ^uintptr(0), uintptr(unsafe.Pointer(&lvi))) {
-			return newError("SendMessage(LVM_SETITEMSTATE)")
-		}
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, ^uintptr(0), uintptr(unsafe.Pointer(&lvi))) {
-			return newError("SendMessage(LVM_SETITEMSTATE)")
+// ResetState restores the default layout of the *TableView and clears the
+// persisted state, e.g. for a "Reset Columns" command.
+//
+// Each column gets back the title, width, visibility and frozen state it had
+// when it was added, or when RestoreState was first called, whichever is
+// later. Columns are displayed in the order of the column list again and the
+// model is sorted as specified by SetInitialSort.
+func (tv *TableView) ResetState() error {
+	if tv.persistent {
+		if err := tv.WriteState(""); err != nil {
+			return err
 		}
 	}
 
-	if index > -1 {
-		lvi.State = win.LVIS_FOCUSED | win.LVIS_SELECTED
-	}
+	tv.endCellEdit(false)
 
-	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi))) {
-		return newError("SendMessage(LVM_SETITEMSTATE)")
+	if err := tv.resetLayout(); err != nil {
+		return err
 	}
-	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(index), uintptr(unsafe.Pointer(&lvi))) {
-		return newError("SendMessage(LVM_SETITEMSTATE)")
+
+	col, order := tv.initialSortColumn, tv.initialSortOrder
+	if col >= tv.columns.Len() {
+		// The column has been removed since, so the default applies.
+		col, order = 0, SortAscending
 	}
 
-	if index != -1 {
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
-			return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	if sorter, ok := tv.model.(Sorter); ok {
+		if sorter.ColumnSortable(col) {
+			tv.sortedColumnIndex = col
+			tv.sortOrder = order
+
+			if err := sorter.Sort(col, order); err != nil {
+				return err
+			}
 		}
-		// Windows bug? Sometimes a second LVM_ENSUREVISIBLE is required.
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
-			return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	} else if tv.sortFunc != nil && tv.sortedBySortFunc {
+		if err := tv.sortFunc(col, order); err != nil {
+			return err
 		}
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
-			return newError("SendMessage(LVM_ENSUREVISIBLE)")
+
+		tv.sortedColumnIndex = col
+		tv.sortOrder = order
+
+		if tv.rowFilter != nil {
+			tv.applyRowFilter()
 		}
-		// Windows bug? Sometimes a second LVM_ENSUREVISIBLE is required.
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_ENSUREVISIBLE, uintptr(index), uintptr(0)) {
-			return newError("SendMessage(LVM_ENSUREVISIBLE)")
+
+		if err := tv.SetSortIndicator(col, order); err != nil {
+			return err
 		}
 	}
 
-	tv.currentIndex = index
-
-	if index == -1 || tv.itemStateChangedEventDelay == 0 {
-		tv.currentIndexChangedPublisher.Publish()
-	}
+	tv.Invalidate()
 
-	if tv.MultiSelection() {
-		tv.updateSelectedIndexes()
-	}
+	tv.columnsOrderChangedPublisher.Publish()
+	tv.sortChangedPublisher.Publish()
 
 	return nil
 }
 
-// CurrentIndexChanged is the event that is published after CurrentIndex has
-// changed.
-func (tv *TableView) CurrentIndexChanged() *Event {
-	return tv.currentIndexChangedPublisher.Event()
-}
+func (tv *TableView) resetLayout() error {
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
 
-// MultiSelection returns whether multiple items can be selected at once.
-//
-// By default only a single item can be selected at once.
-func (tv *TableView) MultiSelection() bool {
-	style := uint(win.GetWindowLong(tv.hwndNormal, win.GWL_STYLE))
-	if style == 0 {
-		lastError("GetWindowLong")
-		return false
+	for _, tvc := range tv.columns.items {
+		if err := tvc.resetToDefaults(); err != nil {
+			return err
+		}
 	}
 
-	return style&win.LVS_SINGLESEL == 0
-}
-
-// SetMultiSelection sets whether multiple items can be selected at once.
-func (tv *TableView) SetMultiSelection(multiSel bool) error {
-	if err := ensureWindowLongBits(tv.hwndFrozen, win.GWL_STYLE, win.LVS_SINGLESEL, !multiSel); err != nil {
+	if err := tv.setColumnDisplayOrder(tv.columns.items); err != nil {
 		return err
 	}
 
-	return ensureWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.LVS_SINGLESEL, !multiSel)
-}
-
-// SelectedIndexes returns the indexes of the currently selected items.
-func (tv *TableView) SelectedIndexes() []int {
-	indexes := make([]int, len(tv.selectedIndexes))
-
-	for i, j := range tv.selectedIndexes {
-		indexes[i] = j
-	}
+	tv.applyColumnWidthFractions()
 
-	return indexes
+	return nil
 }
 
-// SetSelectedIndexes sets the indexes of the currently selected items.
-func (tv *TableView) SetSelectedIndexes(indexes []int) error {
-	tv.inSetSelectedIndexes = true
-	defer func() {
-		tv.inSetSelectedIndexes = false
-		tv.publishSelectedIndexesChanged()
-	}()
-
-	lvi := &win.LVITEM{StateMask: win.LVIS_FOCUSED | win.LVIS_SELECTED}
-	lp := uintptr(unsafe.Pointer(lvi))
+func (tv *TableView) restoreItemPosition(tvs *tableViewState) error {
+	count := tv.RowCount()
 
-	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
-		return newError("SendMessage(LVM_SETITEMSTATE)")
-	}
-	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, ^uintptr(0), lp) {
-		return newError("SendMessage(LVM_SETITEMSTATE)")
+	if tvs.CurrentIndex != nil && *tvs.CurrentIndex < count {
+		if err := tv.SetCurrentIndex(*tvs.CurrentIndex); err != nil {
+			return err
+		}
 	}
 
-	lvi.State = win.LVIS_FOCUSED | win.LVIS_SELECTED
-	for _, i := range indexes {
-		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_SETITEMSTATE, uintptr(i), lp) {
-			return newError("SendMessage(LVM_SETITEMSTATE)")
+	if tvs.TopIndex != nil && *tvs.TopIndex > 0 && *tvs.TopIndex < count {
+		// Scrolling up to an item makes it the topmost visible one.
+		if err := tv.EnsureItemVisible(count - 1); err != nil {
+			return err
 		}
-		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_SETITEMSTATE, uintptr(i), lp) {
-			return newError("SendMessage(LVM_SETITEMSTATE)")
+		if err := tv.EnsureItemVisible(*tvs.TopIndex); err != nil {
+			return err
 		}
 	}
 
-	idxs := make([]int, len(indexes))
+	return nil
+}
 
-	for i, j := range indexes {
-		idxs[i] = j
+// CellText returns the text that is displayed for the cell at row and col.
+//
+// It returns an empty string, if there is no such cell.
+func (tv *TableView) CellText(row, col int) string {
+	if tv.model == nil || col < 0 || col >= tv.columns.Len() {
+		return ""
 	}
 
-	tv.selectedIndexes = idxs
+	if row = tv.ViewToModelIndex(row); row < 0 || row >= tv.model.RowCount() {
+		return ""
+	}
 
-	return nil
+	return tv.cellText(row, col)
 }
 
-func (tv *TableView) updateSelectedIndexes() {
-	count := int(win.SendMessage(tv.hwndNormal, win.LVM_GETSELECTEDCOUNT, 0, 0))
-	indexes := make([]int, count)
+// CellValue returns the value of the model for the cell at row and col, as
+// opposed to CellText, which returns the formatted value.
+//
+// It returns nil, if there is no such cell.
+func (tv *TableView) CellValue(row, col int) interface{} {
+	if tv.model == nil || col < 0 || col >= tv.columns.Len() {
+		return nil
+	}
 
-	j := -1
-	for i := 0; i < count; i++ {
-		j = int(win.SendMessage(tv.hwndNormal, win.LVM_GETNEXTITEM, uintptr(j), win.LVNI_SELECTED))
-		indexes[i] = j
+	if row = tv.ViewToModelIndex(row); row < 0 || row >= tv.model.RowCount() {
+		return nil
 	}
 
-	changed := len(indexes) != len(tv.selectedIndexes)
-	if !changed {
-		for i := 0; i < len(indexes); i++ {
-			if indexes[i] != tv.selectedIndexes[i] {
-				changed = true
-				break
-			}
+	return tv.model.Value(row, col)
+}
+
+func (tv *TableView) cellText(row, col int) string {
+	return tv.formatValue(tv.model.Value(row, col), col)
+}
+
+// displayedCellText returns the text to display for the cell at model row and
+// col, which is a placeholder while the value of an AsyncValuer is not ready.
+func (tv *TableView) displayedCellText(row, col int) string {
+	if av := tv.asyncValuer; av != nil {
+		value, ready := av.ValueAsync(row, col)
+		if !ready {
+			return tableViewAsyncPlaceholder
 		}
-	}
 
-	if changed {
-		tv.selectedIndexes = indexes
-		tv.publishSelectedIndexesChanged()
+		return tv.formatValue(value, col)
 	}
-}
 
-// ItemStateChangedEventDelay returns the delay in milliseconds, between the
-// moment the state of an item in the *TableView changes and the moment the
-// associated event is published.
-//
-// By default there is no delay.
-func (tv *TableView) ItemStateChangedEventDelay() int {
-	return tv.itemStateChangedEventDelay
+	return tv.cellText(row, col)
 }
 
-// SetItemStateChangedEventDelay sets the delay in milliseconds, between the
-// moment the state of an item in the *TableView changes and the moment the
-// associated event is published.
+// AsyncValueReady notifies the *TableView, that values of the model row, that
+// were not ready when requested through AsyncValuer, are ready now, so the
+// row is drawn again.
 //
-// An example where this may be useful is a master-details scenario. If the
-// master TableView is configured to delay the event, you can avoid pointless
-// updates of the details TableView, if the user uses arrow keys to rapidly
-// navigate the master view.
-func (tv *TableView) SetItemStateChangedEventDelay(delay int) {
-	tv.itemStateChangedEventDelay = delay
-}
+// Unlike most other methods, it is safe to call from any goroutine.
+func (tv *TableView) AsyncValueReady(row int) {
+	tv.Synchronize(func() {
+		if tv.model == nil || row < 0 || row >= tv.model.RowCount() {
+			return
+		}
 
-// SelectedIndexesChanged returns the event that is published when the list of
-// selected item indexes changed.
-func (tv *TableView) SelectedIndexesChanged() *Event {
-	return tv.selectedIndexesChangedPublisher.Event()
+		if index := tv.ModelToViewIndex(row); index > -1 {
+			// Only the displayed values changed, so unlike UpdateItem, this
+			// must not resort the model.
+			win.SendMessage(tv.hwndFrozen, win.LVM_REDRAWITEMS, uintptr(index), uintptr(index))
+			win.SendMessage(tv.hwndNormal, win.LVM_REDRAWITEMS, uintptr(index), uintptr(index))
+			tv.invalidatePinnedRows()
+		}
+	})
 }
 
-func (tv *TableView) publishSelectedIndexesChanged() {
-	if tv.itemStateChangedEventDelay > 0 {
-		if 0 == win.SetTimer(
-			tv.hWnd,
-			tableViewSelectedIndexesChangedTimerId,
-			uint32(tv.itemStateChangedEventDelay),
-			0) {
+// formatValue formats value for display in the column at index col.
+func (tv *TableView) formatValue(value interface{}, col int) string {
+	var text string
+	switch val := value.(type) {
+	case string:
+		text = val
 
-			lastError("SetTimer")
+	case float32:
+		text = tv.formatFloat(float64(val), col)
+
+	case float64:
+		text = tv.formatFloat(val, col)
+
+	case time.Time:
+		if val.Year() > 1601 {
+			text = val.Format(tv.columns.items[col].format)
 		}
-	} else {
-		tv.selectedIndexesChangedPublisher.Publish()
+
+	case bool:
+		if val {
+			text = tv.boolCheckedGlyph
+		} else {
+			text = tv.boolUncheckedGlyph
+		}
+
+	case *big.Rat:
+		tvc := tv.columns.items[col]
+
+		prec := tvc.precision
+		if prec == 0 {
+			prec = 2
+		}
+
+		if tvc.numberFormat.percentFactor() != 1 {
+			val = new(big.Rat).Mul(val, big.NewRat(100, 1))
+		}
+
+		text = tvc.numberFormat.formatFloatString(val.FloatString(prec), prec)
+
+	default:
+		text = fmt.Sprintf(tv.columns.items[col].format, val)
 	}
-}
 
-// LastColumnStretched returns if the last column should take up all remaining
-// horizontal space of the *TableView.
-func (tv *TableView) LastColumnStretched() bool {
-	return tv.lastColumnStretched
+	return text
 }
 
-// SetLastColumnStretched sets if the last column should take up all remaining
-// horizontal space of the *TableView.
-//
-// The effect of setting this is persistent.
-func (tv *TableView) SetLastColumnStretched(value bool) error {
-	if value {
-		if err := tv.StretchLastColumn(); err != nil {
-			return err
-		}
+func (tv *TableView) formatFloat(f float64, col int) string {
+	tvc := tv.columns.items[col]
+
+	prec := tvc.precision
+	if prec == 0 {
+		prec = 2
 	}
 
-	tv.lastColumnStretched = value
+	f *= tvc.numberFormat.percentFactor()
 
-	return nil
+	return tvc.numberFormat.formatFloatString(strconv.FormatFloat(f, 'f', prec, 64), prec)
 }
 
-// StretchLastColumn makes the last column take up all remaining horizontal
-// space of the *TableView.
-//
-// The effect of this is not persistent.
-func (tv *TableView) StretchLastColumn() error {
-	colCount := tv.visibleColumnCount()
-	if colCount == 0 {
+// NumberFormat returns the NumberFormat of the column at index col, or nil if
+// it formats numbers by default, i.e. grouped.
+func (tv *TableView) NumberFormat(col int) *NumberFormat {
+	if col < 0 || col >= tv.columns.Len() {
 		return nil
 	}
 
-	if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETCOLUMNWIDTH, uintptr(colCount-1), win.LVSCW_AUTOSIZE_USEHEADER) {
-		return newError("LVM_SETCOLUMNWIDTH failed")
+	if nf := tv.columns.items[col].numberFormat; nf != nil {
+		format := *nf
+		return &format
 	}
 
 	return nil
 }
 
-// Persistent returns if the *TableView should persist its UI state, like column
-// widths. See *App.Settings for details.
-func (tv *TableView) Persistent() bool {
-	return tv.persistent
-}
-
-// SetPersistent sets if the *TableView should persist its UI state, like column
-// widths. See *App.Settings for details.
-func (tv *TableView) SetPersistent(value bool) {
-	tv.persistent = value
-}
+// SetNumberFormat sets how the float32, float64 and *big.Rat values of the
+// column at index col are formatted.
+//
+// The number of decimal places is still determined by the precision of the
+// column.
+func (tv *TableView) SetNumberFormat(col int, format NumberFormat) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
 
-type tableViewState struct {
-	SortColumnName     string
-	SortOrder          SortOrder
-	ColumnDisplayOrder []string // Also indicates visibility
-	Columns            []tableViewColumnState
-}
+	tv.columns.items[col].numberFormat = &format
 
-type tableViewColumnState struct {
-	Name   string
-	Title  string
-	Width  int
-	Frozen bool
+	return tv.Invalidate()
 }
 
-// SaveState writes the UI state of the *TableView to the settings.
-func (tv *TableView) SaveState() error {
-	if tv.columns.Len() == 0 {
-		return nil
-	}
+func (tv *TableView) toggleItemChecked(index int) error {
+	row := tv.ViewToModelIndex(index)
 
-	var tvs tableViewState
+	if tsic := tv.triStateItemChecker; tsic != nil {
+		// Cycle through unchecked, checked and indeterminate.
+		var state CheckState
+		switch tsic.CheckState(row) {
+		case CheckUnchecked:
+			state = CheckChecked
 
-	tvs.SortColumnName = tv.columns.items[tv.sortedColumnIndex].name
-	tvs.SortOrder = tv.sortOrder
+		case CheckChecked:
+			state = CheckIndeterminate
 
-	tvs.Columns = make([]tableViewColumnState, tv.columns.Len())
+		default:
+			state = CheckUnchecked
+		}
 
-	for i, tvc := range tv.columns.items {
-		tvcs := &tvs.Columns[i]
+		if err := tsic.SetCheckState(row, state); err != nil {
+			return wrapError(err)
+		}
+	} else {
+		checked := tv.itemChecker.Checked(row)
 
-		tvcs.Name = tvc.name
-		tvcs.Title = tvc.titleOverride
-		tvcs.Width = tvc.Width()
-		tvcs.Frozen = tvc.Frozen()
+		if err := tv.itemChecker.SetChecked(row, !checked); err != nil {
+			return wrapError(err)
+		}
 	}
 
-	visibleCols := tv.visibleColumns()
-	frozenCount := tv.visibleFrozenColumnCount()
-	normalCount := len(visibleCols) - frozenCount
-	indices := make([]int32, len(visibleCols))
-	var lp uintptr
-	if frozenCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[0]))
-
-		if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_GETCOLUMNORDERARRAY, uintptr(frozenCount), lp) {
-			return newError("LVM_GETCOLUMNORDERARRAY")
-		}
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_UPDATE, uintptr(index), 0) {
+		return newError("SendMessage(LVM_UPDATE)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_UPDATE, uintptr(index), 0) {
+		return newError("SendMessage(LVM_UPDATE)")
 	}
-	if normalCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[frozenCount]))
 
-		if 0 == win.SendMessage(tv.hwndNormal, win.LVM_GETCOLUMNORDERARRAY, uintptr(normalCount), lp) {
-			return newError("LVM_GETCOLUMNORDERARRAY")
-		}
-	}
+	tv.checkAnchorIndex = index
 
-	tvs.ColumnDisplayOrder = make([]string, len(visibleCols))
-	for i, j := range indices {
-		if i >= frozenCount {
-			j += int32(frozenCount)
-		}
-		tvs.ColumnDisplayOrder[i] = visibleCols[j].name
-	}
+	tv.updateCheckedRows(row, row)
 
-	state, err := json.Marshal(tvs)
-	if err != nil {
-		return err
-	}
+	tv.itemCheckedChangedPublisher.Publish(index)
 
-	return tv.WriteState(string(state))
+	return nil
 }
 
-// RestoreState restores the UI state of the *TableView from the settings.
-func (tv *TableView) RestoreState() error {
-	state, err := tv.ReadState()
-	if err != nil {
-		return err
+// checkItemRange gives the items from the item that was toggled last through
+// the item at index the check state of the former, like a shift click in a
+// file manager does. Without such an item, the item at index is toggled.
+func (tv *TableView) checkItemRange(index int) error {
+	anchor := tv.checkAnchorIndex
+	if anchor < 0 || anchor >= tv.itemCount() || anchor == index {
+		return tv.toggleItemChecked(index)
 	}
-	if state == "" {
-		return nil
-	}
-
-	tv.SetSuspended(true)
-	defer tv.SetSuspended(false)
-
-	var tvs tableViewState
 
-	if err := json.Unmarshal(([]byte)(state), &tvs); err != nil {
-		return err
+	from, to := anchor, index
+	if from > to {
+		from, to = to, from
 	}
 
-	name2tvc := make(map[string]*TableViewColumn)
+	anchorRow := tv.ViewToModelIndex(anchor)
 
-	for _, tvc := range tv.columns.items {
-		name2tvc[tvc.name] = tvc
-	}
+	var changed []int
 
-	name2tvcs := make(map[string]*tableViewColumnState)
+	if tsic := tv.triStateItemChecker; tsic != nil {
+		state := tsic.CheckState(anchorRow)
 
-	for i, tvcs := range tvs.Columns {
-		name2tvcs[tvcs.Name] = &tvs.Columns[i]
+		for i := from; i <= to; i++ {
+			row := tv.ViewToModelIndex(i)
 
-		if tvc := name2tvc[tvcs.Name]; tvc != nil {
-			if err := tvc.SetTitleOverride(tvcs.Title); err != nil {
-				return err
-			}
-			if err := tvc.SetWidth(tvcs.Width); err != nil {
-				return err
-			}
-			var visible bool
-			for _, name := range tvs.ColumnDisplayOrder {
-				if name == tvc.name {
-					visible = true
-					break
-				}
-			}
-			if err := tvc.SetVisible(tvc.visible && visible); err != nil {
-				return err
+			if tsic.CheckState(row) == state {
+				continue
 			}
-			if err := tvc.SetFrozen(tvcs.Frozen); err != nil {
-				return err
+
+			if err := tsic.SetCheckState(row, state); err != nil {
+				return wrapError(err)
 			}
+
+			changed = append(changed, i)
 		}
-	}
+	} else {
+		checked := tv.itemChecker.Checked(anchorRow)
 
-	visibleCount := tv.visibleColumnCount()
-	frozenCount := tv.visibleFrozenColumnCount()
-	normalCount := visibleCount - frozenCount
+		for i := from; i <= to; i++ {
+			row := tv.ViewToModelIndex(i)
 
-	indices := make([]int32, visibleCount)
+			if tv.itemChecker.Checked(row) == checked {
+				continue
+			}
 
-	knownNames := make(map[string]struct{})
+			if err := tv.itemChecker.SetChecked(row, checked); err != nil {
+				return wrapError(err)
+			}
 
-	displayOrder := make([]string, 0, visibleCount)
-	for _, name := range tvs.ColumnDisplayOrder {
-		knownNames[name] = struct{}{}
-		if tvc, ok := name2tvc[name]; ok && tvc.visible {
-			displayOrder = append(displayOrder, name)
-		}
-	}
-	for _, tvc := range tv.visibleColumns() {
-		if _, ok := knownNames[tvc.name]; !ok {
-			displayOrder = append(displayOrder, tvc.name)
+			changed = append(changed, i)
 		}
 	}
 
-	for i, tvc := range tv.visibleColumns() {
-		for j, name := range displayOrder {
-			if tvc.name == name && j < visibleCount {
-				idx := i
-				if j >= frozenCount {
-					idx -= frozenCount
-				}
-				indices[j] = int32(idx)
-				break
-			}
-		}
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_REDRAWITEMS, uintptr(from), uintptr(to)) {
+		return newError("SendMessage(LVM_REDRAWITEMS)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_REDRAWITEMS, uintptr(from), uintptr(to)) {
+		return newError("SendMessage(LVM_REDRAWITEMS)")
 	}
 
-	var lp uintptr
-	if frozenCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[0]))
-
-		if 0 == win.SendMessage(tv.hwndFrozen, win.LVM_SETCOLUMNORDERARRAY, uintptr(frozenCount), lp) {
-			return newError("LVM_SETCOLUMNORDERARRAY")
-		}
+	for _, i := range changed {
+		row := tv.ViewToModelIndex(i)
+		tv.updateCheckedRows(row, row)
 	}
-	if normalCount > 0 {
-		lp = uintptr(unsafe.Pointer(&indices[frozenCount]))
 
-		if 0 == win.SendMessage(tv.hwndNormal, win.LVM_SETCOLUMNORDERARRAY, uintptr(normalCount), lp) {
-			return newError("LVM_SETCOLUMNORDERARRAY")
-		}
+	for _, i := range changed {
+		tv.itemCheckedChangedPublisher.Publish(i)
 	}
 
-	for i, c := range tvs.Columns {
-		if c.Name == tvs.SortColumnName && i < visibleCount {
-			tv.sortedColumnIndex = i
-			tv.sortOrder = tvs.SortOrder
-			break
+	return nil
+}
+
+func (tv *TableView) applyImageListForImage(image interface{}) {
+	tv.hIml, tv.usingSysIml, _ = imageListForImage(image)
+
+	tv.applyImageList()
+
+	tv.imageUintptr2Index = make(map[uintptr]int32)
+	tv.filePath2IconIndex = make(map[string]int32)
+}
+
+func (tv *TableView) applyImageList() {
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		var hIml win.HIMAGELIST
+		if tv.displaysImages(hwnd) {
+			hIml = tv.hIml
 		}
+
+		win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, uintptr(hIml))
 	}
 
-	if sorter, ok := tv.model.(Sorter); ok {
-		if !sorter.ColumnSortable(tv.sortedColumnIndex) {
-			for i := range tvs.Columns {
-				if sorter.ColumnSortable(i) {
-					tv.sortedColumnIndex = i
-				}
-			}
-		}
+	tv.applyRowHeightImageList()
+	tv.updateHeaderImages()
+}
 
-		sorter.Sort(tv.sortedColumnIndex, tvs.SortOrder)
+// displaysImages returns if the list view hwnd displays item images.
+//
+// Without a CellImageProvider, only the list view hosting the first column
+// displays images.
+func (tv *TableView) displaysImages(hwnd win.HWND) bool {
+	if tv.hIml == 0 {
+		return false
 	}
 
-	return nil
+	if tv.cellImageProvider != nil {
+		return true
+	}
+
+	return (hwnd == tv.hwndFrozen) == tv.hasFrozenColumn
 }
 
-func (tv *TableView) toggleItemChecked(index int) error {
-	checked := tv.itemChecker.Checked(index)
+// cellImageIndex returns the index in the image list of the image displayed in
+// the cell at model row and col, that is sub item subItem of its list view. ok
+// is false, if the cell displays no image.
+func (tv *TableView) cellImageIndex(row, col int, subItem int32) (index int32, ok bool) {
+	var image interface{}
+	if cip := tv.cellImageProvider; cip != nil && col > -1 {
+		image = cip.ImageForCell(row, col)
+	} else if subItem == 0 {
+		if ip := tv.imageProvider; ip != nil && image == nil {
+			image = ip.Image(row)
+		}
+	}
+	if styler := tv.styler; styler != nil && image == nil {
+		tv.style.row = row
+		tv.style.col = col
+		tv.style.bounds = Rectangle{}
+		tv.style.Image = nil
 
-	if err := tv.itemChecker.SetChecked(index, !checked); err != nil {
-		return wrapError(err)
+		styler.StyleCell(&tv.style)
+
+		image = tv.style.Image
 	}
 
-	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_UPDATE, uintptr(index), 0) {
-		return newError("SendMessage(LVM_UPDATE)")
+	if image == nil {
+		return 0, false
 	}
-	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_UPDATE, uintptr(index), 0) {
-		return newError("SendMessage(LVM_UPDATE)")
+
+	if tv.hIml == 0 {
+		tv.applyImageListForImage(image)
 	}
 
-	return nil
+	return imageIndexMaybeAdd(
+		image,
+		tv.hIml,
+		tv.usingSysIml,
+		tv.imageUintptr2Index,
+		tv.filePath2IconIndex), true
 }
 
-func (tv *TableView) applyImageListForImage(image interface{}) {
-	tv.hIml, tv.usingSysIml, _ = imageListForImage(image)
-
-	tv.applyImageList()
+// checkStateImage returns the one-based index of the state image, that
+// displays the check state of model row.
+func (tv *TableView) checkStateImage(row int) uint32 {
+	if tsic := tv.triStateItemChecker; tsic != nil {
+		switch tsic.CheckState(row) {
+		case CheckChecked:
+			return 2
+
+		case CheckIndeterminate:
+			if tv.hImlCheckState != 0 {
+				return 3
+			}
+			return 2
 
-	tv.imageUintptr2Index = make(map[uintptr]int32)
-	tv.filePath2IconIndex = make(map[string]int32)
-}
+		default:
+			return 1
+		}
+	}
 
-func (tv *TableView) applyImageList() {
-	var hwnd, hwndOther win.HWND
-	if tv.hasFrozenColumn {
-		hwnd, hwndOther = tv.hwndFrozen, tv.hwndNormal
-	} else {
-		hwnd, hwndOther = tv.hwndNormal, tv.hwndFrozen
+	if tv.itemChecker.Checked(row) {
+		return 2
 	}
 
-	win.SendMessage(hwnd, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, uintptr(tv.hIml))
-	win.SendMessage(hwndOther, win.LVM_SETIMAGELIST, win.LVSIL_SMALL, 0)
+	return 1
 }
 
 func (tv *TableView) disposeImageListAndCaches() {
@@ -1383,6 +5204,8 @@ func (tv *TableView) disposeImageListAndCaches() {
 	}
 	tv.hIml = 0
 
+	tv.applyRowHeightImageList()
+
 	tv.imageUintptr2Index = nil
 	tv.filePath2IconIndex = nil
 }
@@ -1436,6 +5259,28 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 	}
 
 	switch msg {
+	case win.WM_PAINT:
+		if tv.busy || len(tv.pinnedRows) > 0 {
+			result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+			if len(tv.pinnedRows) > 0 {
+				tv.drawPinnedRows(hwnd)
+			}
+			if tv.busy {
+				tv.drawBusyOverlay(hwnd)
+			}
+
+			return result
+		}
+
+		if hwnd == tv.hwndNormal && tv.emptyText != "" && tv.itemCount() == 0 {
+			result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+			tv.drawEmptyText()
+
+			return result
+		}
+
 	case win.WM_ERASEBKGND:
 		if tv.lastColumnStretched && !tv.inEraseBkgnd {
 			tv.inEraseBkgnd = true
@@ -1452,13 +5297,64 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 		}
 
 	case win.WM_LBUTTONDOWN, win.WM_RBUTTONDOWN, win.WM_LBUTTONDBLCLK, win.WM_RBUTTONDBLCLK:
+		if row := tv.pinnedRowAt(hwnd, int(win.GET_Y_LPARAM(lp))); row > -1 {
+			win.SetFocus(tv.hwndFrozen)
+			if index := tv.ModelToViewIndex(row); tv.rowSelectable(index) && tv.selectionChangeAllowed(index) {
+				tv.SetCurrentIndex(index)
+			}
+			return 0
+		}
+
 		var hti win.LVHITTESTINFO
 		hti.Pt = win.POINT{win.GET_X_LPARAM(lp), win.GET_Y_LPARAM(lp)}
 		win.SendMessage(hwnd, win.LVM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))
 
+		if hti.IItem > -1 && hti.Flags&win.LVHT_ONITEM != 0 &&
+			!(tv.checkBoxesOnly && tv.itemChecker != nil && tv.CheckBoxes()) &&
+			(!tv.rowSelectable(int(hti.IItem)) || !tv.selectionChangeAllowed(int(hti.IItem))) {
+
+			// Virtual list views do not send LVN_ITEMCHANGING, so we keep
+			// them from selecting the item in the first place. Its check
+			// box still works.
+			if hti.Flags == win.LVHT_ONITEMSTATEICON && tv.itemChecker != nil && tv.CheckBoxes() &&
+				(msg == win.WM_LBUTTONDOWN || msg == win.WM_RBUTTONDOWN) {
+
+				tv.toggleItemChecked(int(hti.IItem))
+			}
+
+			win.SetFocus(tv.hwndFrozen)
+			return 0
+		}
+
+		if tv.checkBoxesOnly && tv.itemChecker != nil && tv.CheckBoxes() &&
+			(msg == win.WM_LBUTTONDOWN || msg == win.WM_LBUTTONDBLCLK) {
+
+			// Clicks toggle check boxes instead of changing the selection.
+			if hti.IItem > -1 && hti.Flags&win.LVHT_ONITEM != 0 {
+				if msg == win.WM_LBUTTONDOWN && ShiftDown() {
+					tv.checkItemRange(int(hti.IItem))
+				} else {
+					tv.toggleItemChecked(int(hti.IItem))
+				}
+			}
+
+			win.SetFocus(tv.hwndFrozen)
+			return 0
+		}
+
+		if hti.IItem > -1 && hti.Flags&win.LVHT_ONITEM != 0 {
+			// The list view selects the item after we return.
+			tv.approvedIndex = int(hti.IItem)
+		}
+
 		if hti.Flags == win.LVHT_NOWHERE {
 			if tv.MultiSelection() {
 				tv.publishNextSelClear = true
+
+				if msg == win.WM_LBUTTONDOWN && tv.marqueeSelection {
+					tv.beginMarquee(hwnd, hti.Pt)
+					return 0
+				}
 			} else {
 				if tv.CheckBoxes() {
 					if tv.currentIndex > -1 {
@@ -1478,91 +5374,339 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 				tv.itemChecker != nil &&
 				tv.CheckBoxes() {
 
-				tv.toggleItemChecked(int(hti.IItem))
+				if msg == win.WM_LBUTTONDOWN && ShiftDown() {
+					tv.checkItemRange(int(hti.IItem))
+				} else {
+					tv.toggleItemChecked(int(hti.IItem))
+				}
+			}
+
+		case win.WM_LBUTTONDBLCLK, win.WM_RBUTTONDBLCLK:
+			if msg == win.WM_LBUTTONDBLCLK && tv.cellSetter != nil {
+				var shti win.LVHITTESTINFO
+				shti.Pt = hti.Pt
+				win.SendMessage(hwnd, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&shti)))
+
+				col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, shti.ISubItem)
+				if shti.IItem > -1 && col > -1 && tv.columns.items[col].editable {
+					tv.EditCell(int(shti.IItem), col)
+					return 0
+				}
+			}
+
+			if tv.currentIndex != tv.prevIndex && tv.itemStateChangedEventDelay > 0 {
+				tv.prevIndex = tv.currentIndex
+				tv.currentIndexChangedPublisher.Publish()
+			}
+		}
+
+	case win.WM_CONTEXTMENU:
+		pt := win.POINT{win.GET_X_LPARAM(lp), win.GET_Y_LPARAM(lp)}
+		row, col := -1, -1
+
+		if pt.X == -1 && pt.Y == -1 {
+			// Requested using the keyboard.
+			pt = win.POINT{}
+
+			if row = tv.currentIndex; row > -1 {
+				rc := win.RECT{Left: win.LVIR_BOUNDS}
+				if win.SendMessage(hwnd, win.LVM_GETITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) != 0 {
+					pt = win.POINT{rc.Left, rc.Bottom}
+				}
+			}
+
+			win.ClientToScreen(hwnd, &pt)
+		} else {
+			var hti win.LVHITTESTINFO
+			hti.Pt = pt
+			win.ScreenToClient(hwnd, &hti.Pt)
+			win.SendMessage(hwnd, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+			if hti.IItem > -1 && hti.Flags&win.LVHT_ONITEM != 0 {
+				row = int(hti.IItem)
+				col = tv.fromLVColIdx(hwnd == tv.hwndFrozen, hti.ISubItem)
+			}
+		}
+
+		tv.itemContextMenuPublisher.Publish(row, col, Point{int(pt.X), int(pt.Y)})
+
+	case win.WM_MOUSEMOVE, win.WM_MOUSELEAVE:
+		if msg == win.WM_MOUSEMOVE && hwnd == tv.rowDragHwnd {
+			tv.updateRowDrag()
+			return 0
+		}
+		if msg == win.WM_MOUSEMOVE && hwnd == tv.marqueeHwnd {
+			tv.updateMarquee()
+			return 0
+		}
+
+		if tv.inMouseEvent {
+			break
+		}
+
+		tv.updateHoveredIndex(hwnd, msg, lp)
+
+		if msg == win.WM_MOUSEMOVE && tv.cellToolTipProvider != nil {
+			tv.updateCellToolTip(hwnd, lp)
+		}
+
+		tv.inMouseEvent = true
+		defer func() {
+			tv.inMouseEvent = false
+		}()
+
+		if msg == win.WM_MOUSEMOVE {
+			y := int(win.GET_Y_LPARAM(lp))
+			lp = uintptr(win.MAKELONG(0, uint16(y)))
+		}
+
+		win.SendMessage(hwndOther, msg, wp, lp)
+
+	case win.WM_LBUTTONUP:
+		if hwnd == tv.rowDragHwnd {
+			tv.endRowDrag(true)
+			return 0
+		}
+		if hwnd == tv.marqueeHwnd {
+			tv.endMarquee(true)
+			return 0
+		}
+
+	case win.WM_CAPTURECHANGED:
+		if hwnd == tv.rowDragHwnd && win.HWND(lp) != hwnd {
+			tv.cancelRowDragOnCaptureLoss()
+		}
+		if hwnd == tv.marqueeHwnd && win.HWND(lp) != hwnd {
+			tv.cancelMarqueeOnCaptureLoss()
+		}
+
+	case win.WM_CHAR:
+		// Ctrl+A also produces a control character, that must not end up in
+		// the incremental search of the list view.
+		if wp == 0x01 && tv.selectAllShortcut && tv.MultiSelection() {
+			return 0
+		}
+
+		// The incremental search would select the item it finds.
+		if tv.checkBoxesOnly && tv.itemChecker != nil && tv.CheckBoxes() {
+			return 0
+		}
+
+	case win.WM_MOUSEWHEEL:
+		if win.LOWORD(uint32(wp))&win.MK_SHIFT != 0 {
+			// The frozen list view passes the message on to the normal one,
+			// so only the latter scrolls.
+			if hwnd == tv.hwndNormal {
+				tv.scrollHorizontallyByWheel(int(int16(win.HIWORD(uint32(wp)))))
+			}
+			return 0
+		}
+
+	case win.WM_KEYDOWN:
+		if wp == win.VK_ESCAPE && tv.rowDragHwnd != 0 {
+			tv.endRowDrag(false)
+			return 0
+		}
+		if wp == win.VK_ESCAPE && tv.marqueeHwnd != 0 {
+			tv.endMarquee(false)
+			return 0
+		}
+
+		if tv.checkBoxesOnly && tv.itemChecker != nil && tv.CheckBoxes() {
+			// Like clicks, keys must not change the selection.
+			switch wp {
+			case win.VK_UP, win.VK_DOWN, win.VK_PRIOR, win.VK_NEXT, win.VK_HOME, win.VK_END:
+				tv.moveFocusedItem(hwnd, wp)
+				return 0
+
+			case win.VK_SPACE:
+				if index := int(int32(win.SendMessage(hwnd, win.LVM_GETNEXTITEM, ^uintptr(0), win.LVNI_FOCUSED))); index > -1 {
+					tv.toggleItemChecked(index)
+				}
+				return 0
+			}
+		}
+
+		if wp == win.VK_SPACE &&
+			tv.currentIndex > -1 &&
+			tv.itemChecker != nil &&
+			tv.CheckBoxes() {
+
+			tv.toggleItemChecked(tv.currentIndex)
+		}
+
+		if wp == 'C' && ControlDown() && tv.clipboardCopyEnabled {
+			tv.CopySelectionToClipboard()
+			return 0
+		}
+
+		if wp == 'A' && ControlDown() && tv.selectAllShortcut && tv.MultiSelection() {
+			tv.SelectAll()
+			return 0
+		}
+
+		if (wp == win.VK_HOME || wp == win.VK_END) && tv.itemCount() > 0 {
+			// The list views do not reliably keep each other in sync here.
+			target := tv.nextSelectableIndex(0, 1)
+			if wp == win.VK_END {
+				target = tv.nextSelectableIndex(tv.itemCount()-1, -1)
+			}
+
+			if target > -1 {
+				tv.jumpToItem(hwnd, target, tv.MultiSelection() && ShiftDown())
+			}
+			return 0
+		}
+
+		if (wp == win.VK_PRIOR || wp == win.VK_NEXT) && (tv.rowSelectability != nil || tv.selectionChanging != nil) && tv.itemCount() > 0 && !ControlDown() {
+			// Skip items that are not selectable, like the arrow keys do.
+			step := 1
+			if wp == win.VK_PRIOR {
+				step = -1
+			}
+
+			from := tv.currentIndex + step*maxi(1, tv.RowsPerPage()-1)
+			from = maxi(0, mini(tv.itemCount()-1, from))
+
+			target := tv.nextSelectableIndex(from, step)
+			if target == -1 {
+				target = tv.nextSelectableIndex(from, -step)
+			}
+
+			if target > -1 {
+				tv.jumpToItem(hwnd, target, tv.MultiSelection() && ShiftDown())
+			}
+			return 0
+		}
+
+		if (wp == win.VK_UP || wp == win.VK_DOWN) && (tv.rowSelectability != nil || tv.selectionChanging != nil || tv.wrapsNavigation()) && !ControlDown() {
+			// Skip items that are not selectable and wrap around, if enabled.
+			step := 1
+			if wp == win.VK_UP {
+				step = -1
 			}
 
-		case win.WM_LBUTTONDBLCLK, win.WM_RBUTTONDBLCLK:
-			if tv.currentIndex != tv.prevIndex && tv.itemStateChangedEventDelay > 0 {
-				tv.prevIndex = tv.currentIndex
-				tv.currentIndexChangedPublisher.Publish()
+			target := tv.nextSelectableIndex(tv.currentIndex+step, step)
+			if target == -1 && tv.wrapsNavigation() {
+				from := 0
+				if step == -1 {
+					from = tv.itemCount() - 1
+				}
+
+				target = tv.nextSelectableIndex(from, step)
+			}
+
+			if target > -1 {
+				tv.jumpToItem(hwnd, target, tv.MultiSelection() && ShiftDown())
 			}
+			return 0
 		}
 
-	case win.WM_MOUSEMOVE, win.WM_MOUSELEAVE:
-		if tv.inMouseEvent {
-			break
+		if wp == win.VK_DELETE && tv.deleteKeyHandler != nil {
+			if indexes := tv.SelectedIndexes(); len(indexes) > 0 {
+				tv.deleteKeyHandler(indexes)
+				return 0
+			}
 		}
-		tv.inMouseEvent = true
-		defer func() {
-			tv.inMouseEvent = false
-		}()
 
-		if msg == win.WM_MOUSEMOVE {
-			y := int(win.GET_Y_LPARAM(lp))
-			lp = uintptr(win.MAKELONG(0, uint16(y)))
+		if wp == win.VK_F2 && tv.currentIndex > -1 && tv.cellSetter != nil {
+			if col := tv.firstEditableColumn(); col > -1 {
+				tv.EditCell(tv.currentIndex, col)
+				return 0
+			}
 		}
 
-		win.SendMessage(hwndOther, msg, wp, lp)
+		switch wp {
+		case win.VK_UP, win.VK_DOWN, win.VK_PRIOR, win.VK_NEXT:
+			// The list view scrolls to the new current item without
+			// sending LVN_ENDSCROLL.
+			result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
 
-	case win.WM_KEYDOWN:
-		if wp == win.VK_SPACE &&
-			tv.currentIndex > -1 &&
-			tv.itemChecker != nil &&
-			tv.CheckBoxes() {
+			tv.publishVisibleRangeChangedIfNeeded()
 
-			tv.toggleItemChecked(tv.currentIndex)
+			return result
 		}
 
 	case win.WM_NOTIFY:
 		switch ((*win.NMHDR)(unsafe.Pointer(lp))).Code {
-		case win.LVN_GETDISPINFO:
-			di := (*win.NMLVDISPINFO)(unsafe.Pointer(lp))
+		case win.NM_RCLICK:
+			hwndHeader := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+			if ((*win.NMHDR)(unsafe.Pointer(lp))).HwndFrom != hwndHeader {
+				break
+			}
+
+			var hti win.HDHITTESTINFO
+			win.GetCursorPos(&hti.Pt)
+			win.ScreenToClient(hwndHeader, &hti.Pt)
+
+			if -1 == int32(win.SendMessage(hwndHeader, win.HDM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))) ||
+				hti.Flags&win.HHT_ONHEADER == 0 {
 
-			row := int(di.Item.IItem)
-			col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, di.Item.ISubItem)
-			if col == -1 {
 				break
 			}
 
-			if di.Item.Mask&win.LVIF_TEXT > 0 {
-				var text string
-				switch val := tv.model.Value(row, col).(type) {
-				case string:
-					text = val
-
-				case float32:
-					prec := tv.columns.items[col].precision
-					if prec == 0 {
-						prec = 2
-					}
-					text = FormatFloatGrouped(float64(val), prec)
+			if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, hti.IItem); col > -1 {
+				tv.columnHeaderClickedPublisher.Publish(col, RightButton, ModifiersDown())
+			}
 
-				case float64:
-					prec := tv.columns.items[col].precision
-					if prec == 0 {
-						prec = 2
-					}
-					text = FormatFloatGrouped(val, prec)
+		case win.LVN_GETINFOTIP:
+			if tv.cellToolTipProvider == nil {
+				break
+			}
 
-				case time.Time:
-					if val.Year() > 1601 {
-						text = val.Format(tv.columns.items[col].format)
-					}
+			it := (*nmLVGetInfoTip)(unsafe.Pointer(lp))
 
-				case bool:
-					if val {
-						text = checkmark
-					}
+			// The list view reports the item only, so we hit test the sub
+			// item at the cursor.
+			var pt win.POINT
+			if !win.GetCursorPos(&pt) || !win.ScreenToClient(hwnd, &pt) {
+				break
+			}
 
-				case *big.Rat:
-					prec := tv.columns.items[col].precision
-					if prec == 0 {
-						prec = 2
-					}
-					text = formatBigRatGrouped(val, prec)
+			row, col := tv.lvCellAt(hwnd, pt.X, pt.Y)
+			tv.cellToolTipRow, tv.cellToolTipCol = row, col
+			if row == -1 || col == -1 || row != tv.ViewToModelIndex(int(it.IItem)) || it.CchTextMax <= 0 {
+				break
+			}
+
+			text := tv.cellToolTipProvider.CellToolTip(row, col)
+			if text == "" {
+				break
+			}
+
+			utf16 := syscall.StringToUTF16(text)
+			buf := (*[1 << 20]uint16)(unsafe.Pointer(it.PszText))[:it.CchTextMax:it.CchTextMax]
+			n := copy(buf, utf16)
+			buf[n-1] = 0
+
+		case win.LVN_GETDISPINFO:
+			if counter := tv.dispInfoCounter; counter != nil {
+				atomic.AddUint64(counter, 1)
+			}
+
+			di := (*win.NMLVDISPINFO)(unsafe.Pointer(lp))
+
+			row := tv.ViewToModelIndex(int(di.Item.IItem))
+			col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, di.Item.ISubItem)
+			rowNumber := tv.isRowNumberColumn(hwnd, di.Item.ISubItem)
+			if row == -1 || col == -1 && !rowNumber {
+				break
+			}
+			if tv.updateDepth > 0 && row >= tv.model.RowCount() {
+				// The item count is updated when the batch ends.
+				break
+			}
+
+			if tv.populator != nil {
+				tv.populateAheadOf(int(di.Item.IItem))
+			}
 
-				default:
-					text = fmt.Sprintf(tv.columns.items[col].format, val)
+			if di.Item.Mask&win.LVIF_TEXT > 0 {
+				var text string
+				if rowNumber {
+					text = strconv.Itoa(int(di.Item.IItem) + 1)
+				} else {
+					text = tv.displayedCellText(row, col)
 				}
 
 				utf16 := syscall.StringToUTF16(text)
@@ -1572,56 +5716,36 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 				(*buf)[max-1] = 0
 			}
 
-			if (tv.imageProvider != nil || tv.styler != nil) && di.Item.Mask&win.LVIF_IMAGE > 0 {
-				var image interface{}
-				if di.Item.ISubItem == 0 {
-					if ip := tv.imageProvider; ip != nil && image == nil {
-						image = ip.Image(row)
-					}
-				}
-				if styler := tv.styler; styler != nil && image == nil {
-					tv.style.row = row
-					tv.style.col = col
-					tv.style.bounds = Rectangle{}
-					tv.style.Image = nil
-
-					styler.StyleCell(&tv.style)
-
-					image = tv.style.Image
-				}
-
-				if image != nil {
-					if tv.hIml == 0 {
-						tv.applyImageListForImage(image)
-					}
-
-					di.Item.IImage = imageIndexMaybeAdd(
-						image,
-						tv.hIml,
-						tv.usingSysIml,
-						tv.imageUintptr2Index,
-						tv.filePath2IconIndex)
+			if (tv.imageProvider != nil || tv.cellImageProvider != nil || tv.styler != nil) && di.Item.Mask&win.LVIF_IMAGE > 0 {
+				if index, ok := tv.cellImageIndex(row, col, di.Item.ISubItem); ok {
+					di.Item.IImage = index
 				}
 			}
 
 			if di.Item.ISubItem == 0 && di.Item.StateMask&win.LVIS_STATEIMAGEMASK > 0 &&
 				tv.itemChecker != nil {
-				checked := tv.itemChecker.Checked(row)
+				di.Item.State = tv.checkStateImage(row) << 12
+			}
 
-				if checked {
-					di.Item.State = 0x2000
-				} else {
-					di.Item.State = 0x1000
+		case win.NM_CUSTOMDRAW:
+			if nmcd := (*win.NMCUSTOMDRAW)(unsafe.Pointer(lp)); nmcd.Hdr.HwndFrom != hwnd {
+				// The header or tool tip of the list view asks.
+				result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+				if hwndHeader := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0)); nmcd.Hdr.HwndFrom == hwndHeader {
+					result |= tv.customDrawHeaderCheckBoxes(hwnd, hwndHeader, nmcd)
 				}
+
+				return result
 			}
 
-		case win.NM_CUSTOMDRAW:
 			nmlvcd := (*win.NMLVCUSTOMDRAW)(unsafe.Pointer(lp))
 
 			if nmlvcd.IIconPhase == 0 {
 				row := int(nmlvcd.Nmcd.DwItemSpec)
+				modelRow := tv.ViewToModelIndex(row)
 				col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmlvcd.ISubItem)
-				if col == -1 {
+				if col == -1 && !tv.isRowNumberColumn(hwnd, nmlvcd.ISubItem) {
 					break
 				}
 
@@ -1632,16 +5756,16 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 				case win.CDDS_ITEMPREPAINT:
 					tv.customDrawItemHot = nmlvcd.Nmcd.UItemState&win.CDIS_HOT != 0
 
-					if tv.alternatingRowBGColor != 0 {
-						if row%2 == 1 {
-							tv.style.BackgroundColor = tv.alternatingRowBGColor
-						} else {
-							tv.style.BackgroundColor = defaultTVRowBGColor
-						}
+					if tv.alternatingRowBGColor != 0 && row%2 == 1 {
+						tv.style.BackgroundColor = tv.alternatingRowBGColor
+					} else {
+						tv.style.BackgroundColor = defaultTVRowBGColor
 					}
 
+					// A styler may override the alternating background
+					// color for the whole row.
 					if tv.styler != nil {
-						tv.style.row = row
+						tv.style.row = modelRow
 						tv.style.col = -1
 
 						tv.style.bounds = rectangleFromRECT(nmlvcd.Nmcd.Rc)
@@ -1653,35 +5777,81 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 						tv.styler.StyleCell(&tv.style)
 					}
 
+					if tv.itemFlashes != nil {
+						tv.style.BackgroundColor = tv.itemFlashColor(modelRow, tv.style.BackgroundColor)
+					}
+
+					tv.customDrawItemSelected = nmlvcd.Nmcd.UItemState&win.CDIS_SELECTED != 0 &&
+						(tv.selectedRowBGColor != 0 || tv.selectedRowTextColor != 0)
+
+					var selectedTextColor Color
+					if tv.customDrawItemSelected {
+						// We draw the selection ourselves, so the list view
+						// must not paint over it.
+						nmlvcd.Nmcd.UItemState &^= win.CDIS_SELECTED
+
+						tv.style.BackgroundColor, selectedTextColor = tv.selectionColors()
+					}
+
+					tv.itemBGColor = tv.style.BackgroundColor
+
 					if tv.style.BackgroundColor != defaultTVRowBGColor {
 						if brush, _ := NewSolidColorBrush(tv.style.BackgroundColor); brush != nil {
 							defer brush.Dispose()
 
+							bounds := rectangleFromRECT(nmlvcd.Nmcd.Rc)
+							if tv.Gridlines() {
+								// Leave the horizontal gridline below the row intact.
+								bounds.Height--
+							}
+
 							canvas, _ := newCanvasFromHDC(nmlvcd.Nmcd.Hdc)
-							canvas.FillRectangle(brush, rectangleFromRECT(nmlvcd.Nmcd.Rc))
+							canvas.FillRectangle(brush, bounds)
 						}
 					}
 
 					nmlvcd.ClrTextBk = win.COLORREF(tv.style.BackgroundColor)
+					if tv.customDrawItemSelected {
+						nmlvcd.ClrText = win.COLORREF(selectedTextColor)
+					}
+
+					if tv.rowDropIndex > -1 {
+						return win.CDRF_NOTIFYSUBITEMDRAW | win.CDRF_NOTIFYPOSTPAINT
+					}
 
 					return win.CDRF_NOTIFYSUBITEMDRAW
 
+				case win.CDDS_ITEMPOSTPAINT:
+					if tv.rowDropIndex > -1 {
+						tv.drawRowDropMarker(nmlvcd.Nmcd.Hdc, row, nmlvcd.Nmcd.Rc)
+					}
+
 				case win.CDDS_ITEMPREPAINT | win.CDDS_SUBITEM:
+					if col == -1 {
+						tv.prepareRowNumberCell(nmlvcd)
+
+						return win.CDRF_NEWFONT | win.CDRF_SKIPPOSTPAINT
+					}
+
+					textColor := RGB(0, 0, 0)
+					if tv.customDrawItemSelected {
+						nmlvcd.Nmcd.UItemState &^= win.CDIS_SELECTED
+
+						_, textColor = tv.selectionColors()
+
+						nmlvcd.ClrTextBk = win.COLORREF(tv.itemBGColor)
+						nmlvcd.ClrText = win.COLORREF(textColor)
+					}
+
 					if tv.styler != nil {
-						tv.style.row = row
+						tv.style.row = modelRow
 						tv.style.col = col
 
-						if tv.alternatingRowBGColor != 0 {
-							if row%2 == 1 {
-								tv.style.BackgroundColor = tv.alternatingRowBGColor
-							} else {
-								tv.style.BackgroundColor = defaultTVRowBGColor
-							}
-						}
+						tv.style.BackgroundColor = tv.itemBGColor
 
 						tv.style.bounds = rectangleFromRECT(nmlvcd.Nmcd.Rc)
 						tv.style.hdc = nmlvcd.Nmcd.Hdc
-						tv.style.TextColor = RGB(0, 0, 0)
+						tv.style.TextColor = textColor
 						tv.style.Font = nil
 						tv.style.Image = nil
 
@@ -1705,10 +5875,51 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 
 						if font := tv.style.Font; font != nil {
 							win.SelectObject(nmlvcd.Nmcd.Hdc, win.HGDIOBJ(font.handleForDPI(0)))
+
+							tv.growRowHeightForStyledFont(font)
+						}
+					}
+
+					if tv.columns.At(col).kind == ColumnKindProgressBar && modelRow > -1 {
+						bg, text := tv.itemBGColor, textColor
+						if tv.styler != nil {
+							bg, text = tv.style.BackgroundColor, tv.style.TextColor
+						}
+						if nmlvcd.Nmcd.UItemState&win.CDIS_SELECTED != 0 {
+							// We draw the cell ourselves, so the list view
+							// will not highlight it.
+							bg, text = tv.selectionColors()
+						}
+
+						tv.drawProgressBarCell(hwnd, nmlvcd, modelRow, col, bg, text)
+
+						return win.CDRF_SKIPDEFAULT
+					}
+
+					// Wrapped and rich text is drawn in the post paint stage,
+					// over the invisible text of the list view.
+					tv.customDrawRichText = tv.richText(modelRow, col)
+					if tv.columns.At(col).wrapText || tv.customDrawRichText != nil {
+						tv.wrapTextColor = tv.hideDefaultCellText(nmlvcd)
+						tv.wrapTextFont = tv.Font()
+						if tv.styler != nil && tv.style.Font != nil {
+							tv.wrapTextFont = tv.style.Font
 						}
+
+						return win.CDRF_NEWFONT | win.CDRF_NOTIFYPOSTPAINT
 					}
 
 					return win.CDRF_NEWFONT | win.CDRF_SKIPPOSTPAINT
+
+				case win.CDDS_ITEMPOSTPAINT | win.CDDS_SUBITEM:
+					runs := tv.customDrawRichText
+					tv.customDrawRichText = nil
+
+					if runs != nil {
+						tv.drawRichTextCell(hwnd, nmlvcd, col, runs)
+					} else if modelRow > -1 && col > -1 && tv.columns.At(col).wrapText {
+						tv.drawWrappedCellText(hwnd, nmlvcd, modelRow, col)
+					}
 				}
 
 				return win.CDRF_SKIPPOSTPAINT
@@ -1717,6 +5928,8 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			return win.CDRF_SKIPPOSTPAINT
 
 		case win.LVN_BEGINSCROLL:
+			tv.endCellEdit(true)
+
 			if tv.scrolling {
 				break
 			}
@@ -1731,12 +5944,52 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			nmlvs := (*win.NMLVSCROLL)(unsafe.Pointer(lp))
 			win.SendMessage(hwndOther, win.LVM_SCROLL, 0, uintptr(nmlvs.Dy*(rc.Bottom-rc.Top)))
 
+		case win.LVN_ENDSCROLL:
+			tv.publishVisibleRangeChangedIfNeeded()
+			tv.invalidateSummaryRow()
+			tv.invalidatePinnedRows()
+			tv.invalidateColumnGroups()
+
+		case win.LVN_BEGINDRAG:
+			nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
+
+			tv.beginRowDrag(hwnd, int(nmlv.IItem))
+
 		case win.LVN_COLUMNCLICK:
 			nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
 
 			col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmlv.ISubItem)
+			if col == -1 {
+				// The row number column is not sortable.
+				break
+			}
+
+			if ms, ok := tv.model.(MultiSorter); ok && ms.ColumnSortable(col) && ControlDown() {
+				cols := append([]int(nil), ms.SortedColumns()...)
+				orders := append([]SortOrder(nil), ms.SortOrders()...)
+
+				i := 0
+				for i < len(cols) && cols[i] != col {
+					i++
+				}
+
+				if i < len(cols) {
+					if orders[i] == SortAscending {
+						orders[i] = SortDescending
+					} else {
+						orders[i] = SortAscending
+					}
+				} else {
+					cols = append(cols, col)
+					orders = append(orders, SortAscending)
+				}
+
+				tv.sortedColumnIndex = cols[0]
+				tv.sortOrder = orders[0]
+				ms.SortBy(cols, orders)
 
-			if sorter, ok := tv.model.(Sorter); ok && sorter.ColumnSortable(col) {
+				tv.sortChangedPublisher.Publish()
+			} else if sorter, ok := tv.model.(Sorter); ok && sorter.ColumnSortable(col) {
 				prevCol := sorter.SortedColumn()
 				var order SortOrder
 				if col != prevCol || sorter.SortOrder() == SortDescending {
@@ -1747,11 +6000,38 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 				tv.sortedColumnIndex = col
 				tv.sortOrder = order
 				sorter.Sort(col, order)
+
+				tv.sortChangedPublisher.Publish()
+			} else if tv.sortFunc != nil {
+				order := SortAscending
+				if tv.sortedBySortFunc && col == tv.sortedColumnIndex && tv.sortOrder == SortAscending {
+					order = SortDescending
+				}
+
+				if err := tv.sortFunc(col, order); err == nil {
+					tv.sortedBySortFunc = true
+					tv.sortedColumnIndex = col
+					tv.sortOrder = order
+					if tv.rowFilter != nil {
+						tv.applyRowFilter()
+					}
+					tv.rebuildCheckedRows()
+					tv.remapItemFlashes()
+					tv.SetSortIndicator(col, order)
+					tv.Invalidate()
+
+					tv.sortChangedPublisher.Publish()
+				}
 			}
 
 			tv.columnClickedPublisher.Publish(col)
+			tv.columnHeaderClickedPublisher.Publish(col, LeftButton, ModifiersDown())
 
 		case win.LVN_ITEMCHANGED:
+			if tv.inSelectionRemap {
+				break
+			}
+
 			nmlv := (*win.NMLISTVIEW)(unsafe.Pointer(lp))
 			if nmlv.IItem == -1 && !tv.publishNextSelClear {
 				break
@@ -1760,6 +6040,34 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 
 			selectedNow := nmlv.UNewState&win.LVIS_SELECTED > 0
 			selectedBefore := nmlv.UOldState&win.LVIS_SELECTED > 0
+			if selectedNow && !selectedBefore && nmlv.IItem > -1 && !tv.rowSelectable(int(nmlv.IItem)) {
+				// Clicks and keys cannot select such items, but ranges
+				// selected by the list view itself may include them.
+				if tv.MultiSelection() {
+					tv.deselectItem(int(nmlv.IItem))
+				} else if 0 == win.SetTimer(tv.hWnd, tableViewSelectionVetoedTimerId, 1, 0) {
+					// Like a vetoed selection change, we restore the previous
+					// selection once the list view is done.
+					lastError("SetTimer")
+				}
+				break
+			}
+			approved := int(nmlv.IItem) == tv.approvedIndex
+			if selectedNow {
+				tv.approvedIndex = -1
+			}
+			if selectedNow && !selectedBefore && !approved && !tv.inSetCurrentIndex && nmlv.IItem > -1 &&
+				!tv.selectionChangeAllowed(int(nmlv.IItem)) {
+
+				// Clicks and navigation keys are vetoed before the list view
+				// handles them, but its type-ahead search is not, so we
+				// restore the previous selection once the list view is done.
+				if 0 == win.SetTimer(tv.hWnd, tableViewSelectionVetoedTimerId, 1, 0) {
+					lastError("SetTimer")
+				}
+				break
+			}
+
 			if selectedNow && !selectedBefore {
 				tv.prevIndex = tv.currentIndex
 				tv.currentIndex = int(nmlv.IItem)
@@ -1789,6 +6097,25 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 		case win.LVN_ODSTATECHANGED:
 			tv.updateSelectedIndexes()
 
+		case win.NM_CLICK:
+			// The list view swallows WM_LBUTTONUP while it detects drags, so
+			// we learn about clicks this way.
+			nmia := (*win.NMITEMACTIVATE)(unsafe.Pointer(lp))
+			if nmia.Hdr.HwndFrom != hwnd {
+				// The header was clicked.
+				break
+			}
+
+			var hti win.LVHITTESTINFO
+			hti.Pt = nmia.PtAction
+			win.SendMessage(hwnd, win.LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+			if hti.IItem > -1 && hti.Flags&win.LVHT_ONITEM != 0 {
+				if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, hti.ISubItem); col > -1 {
+					tv.cellClickedPublisher.Publish(int(hti.IItem), col)
+				}
+			}
+
 		case win.LVN_ITEMACTIVATE:
 			nmia := (*win.NMITEMACTIVATE)(unsafe.Pointer(lp))
 
@@ -1804,10 +6131,103 @@ func (tv *TableView) lvWndProc(origWndProcPtr uintptr, hwnd win.HWND, msg uint32
 			tv.itemActivatedPublisher.Publish()
 
 		case win.HDN_ITEMCHANGING:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if nmh.Pitem != nil && nmh.Pitem.Mask&win.HDI_WIDTH != 0 {
+				if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmh.IItem); col > -1 {
+					tvc := tv.columns.items[col]
+
+					if width := tvc.constrainedWidth(int(nmh.Pitem.Cxy)); width != int(nmh.Pitem.Cxy) {
+						// Reject the change and apply the nearest allowed
+						// width instead.
+						tvc.clampWidth(width)
+
+						return win.TRUE
+					}
+				}
+			}
+
+			tv.endCellEdit(true)
+
 			tv.updateLVSizes()
+
+		case win.HDN_ITEMCHANGED:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if nmh.Pitem != nil && nmh.Pitem.Mask&win.HDI_WIDTH != 0 {
+				if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmh.IItem); col > -1 && !tv.clampingColumnWidth {
+					tv.columnWidthChangedPublisher.Publish(col)
+				}
+
+				tv.invalidateSummaryRow()
+				tv.invalidatePinnedRows()
+				tv.invalidateColumnGroups()
+				tv.updateHeaderToolTips()
+			}
+
+		case win.HDN_ITEMSTATEICONCLICK:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmh.IItem); col > -1 && tv.columns.items[col].headerCheckBox {
+				tv.toggleAllItemsChecked()
+			}
+
+			return 0
+
+		case win.HDN_DROPDOWN:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmh.IItem); col > -1 {
+				tv.endCellEdit(true)
+
+				tv.columnFilterClickedPublisher.Publish(col, tv.ColumnFilterButtonBounds(col))
+			}
+
+		case win.HDN_DIVIDERDBLCLICK:
+			// The list view cannot measure the content of virtual items, so
+			// we do it ourselves.
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, nmh.IItem); col > -1 {
+				tv.sizeColumnToContent(tv.columns.items[col])
+				tv.updateLVSizes()
+			}
+
+			return 0
+
+		case win.HDN_BEGINDRAG:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if tv.lvColumnFixed(hwnd, nmh.IItem) || !tv.columnDragAllowed(hwnd, nmh.IItem, -1) {
+				return win.TRUE
+			}
+
+		case win.HDN_ENDDRAG:
+			nmh := (*nmHeader)(unsafe.Pointer(lp))
+
+			if nmh.Pitem != nil && nmh.Pitem.Mask&win.HDI_ORDER != 0 && nmh.Pitem.IOrder > -1 &&
+				(!tv.columnDropAllowed(hwnd, nmh.IItem, nmh.Pitem.IOrder) ||
+					!tv.columnGroupsAllowDrop(hwnd, nmh.IItem, nmh.Pitem.IOrder) ||
+					!tv.columnDragAllowed(hwnd, nmh.IItem, nmh.Pitem.IOrder)) {
+
+				return win.TRUE
+			}
+
+			// The header applies the new order after we return, so we
+			// publish the event a little later.
+			if 0 == win.SetTimer(tv.hWnd, tableViewColumnsOrderChangedTimerId, 1, 0) {
+				lastError("SetTimer")
+			}
 		}
 
 	case win.WM_UPDATEUISTATE:
+		if tv.focusRectangleVisible {
+			if win.LOWORD(uint32(wp)) == win.UIS_SET {
+				wp &^= win.UISF_HIDEFOCUS << 16
+			}
+			break
+		}
+
 		switch win.LOWORD(uint32(wp)) {
 		case win.UIS_SET:
 			wp |= win.UISF_HIDEFOCUS << 16
@@ -1843,8 +6263,46 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 			}
 		}
 
+		tv.endCellEdit(true)
+
+		tv.applyColumnWidthFractions()
 		tv.updateLVSizes()
 
+		tv.scheduleVisibleRangeChanged()
+
+	case win.WM_PAINT:
+		if tv.summaryRowProvider == nil && len(tv.columnGroups) == 0 {
+			break
+		}
+
+		var ps win.PAINTSTRUCT
+
+		hdc := win.BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newError("BeginPaint failed")
+			return 0
+		}
+		defer win.EndPaint(hwnd, &ps)
+
+		if tv.summaryRowProvider != nil {
+			tv.drawSummaryRow(hdc)
+		}
+		if len(tv.columnGroups) > 0 {
+			tv.drawColumnGroups(hdc)
+		}
+
+		return 0
+
+	case win.WM_COMMAND:
+		if win.HWND(lp) == tv.hwndCellEdit && tv.hwndCellEdit != 0 && win.HIWORD(uint32(wp)) == win.CBN_SELENDOK {
+			// The drop down list of a combo box cell editor was closed by
+			// choosing a value. We must not destroy the combo box while it is
+			// notifying us, so we commit a little later.
+			if 0 == win.SetTimer(tv.hWnd, tableViewCellEditCommitTimerId, 1, 0) {
+				lastError("SetTimer")
+			}
+		}
+
 	case win.WM_TIMER:
 		if !win.KillTimer(tv.hWnd, wp) {
 			lastError("KillTimer")
@@ -1857,7 +6315,38 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 			}
 
 		case tableViewSelectedIndexesChangedTimerId:
+			tv.invalidatePinnedRows()
+			tv.publishSelectionDeltaChanged()
 			tv.selectedIndexesChangedPublisher.Publish()
+
+		case tableViewColumnsOrderChangedTimerId:
+			tv.invalidateSummaryRow()
+			tv.invalidatePinnedRows()
+			tv.invalidateColumnGroups()
+			tv.updateHeaderToolTips()
+			tv.columnsOrderChangedPublisher.Publish()
+
+		case tableViewSelectionVetoedTimerId:
+			tv.setCurrentIndexOrNone(tv.currentIndex)
+
+		case tableViewVisibleRangeChangedTimerId:
+			tv.publishVisibleRangeChangedIfNeeded()
+
+		case tableViewStyledRowHeightTimerId:
+			tv.updateRowHeight()
+			tv.Invalidate()
+
+		case tableViewCellEditCommitTimerId:
+			tv.endCellEdit(true)
+
+		case tableViewItemFlashTimerId:
+			tv.updateItemFlashes()
+
+		case tableViewItemHoveredTimerId:
+			if tv.pendingHoveredIndex != tv.hoveredIndex {
+				tv.hoveredIndex = tv.pendingHoveredIndex
+				tv.itemHoveredPublisher.Publish(tv.hoveredIndex)
+			}
 		}
 
 	case win.WM_DESTROY:
@@ -1871,27 +6360,86 @@ func (tv *TableView) WndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr
 		if tv.normalLVOrigWndProcPtr != 0 {
 			win.SetWindowLongPtr(tv.hwndNormal, win.GWLP_WNDPROC, tv.normalLVOrigWndProcPtr)
 		}
+		if tv.frozenHeaderOrigWndProcPtr != 0 {
+			win.SetWindowLongPtr(tv.frozenHeaderHwnd(), win.GWLP_WNDPROC, tv.frozenHeaderOrigWndProcPtr)
+		}
+		if tv.normalHeaderOrigWndProcPtr != 0 {
+			win.SetWindowLongPtr(tv.normalHeaderHwnd(), win.GWLP_WNDPROC, tv.normalHeaderOrigWndProcPtr)
+		}
 	}
 
 	return tv.WidgetBase.WndProc(hwnd, msg, wp, lp)
 }
 
+// ContentWidth returns the sum of the widths of the visible columns, that are
+// not frozen, in pixels.
+//
+// A horizontal scroll bar is displayed, if this exceeds the width that is
+// left next to the frozen columns.
+func (tv *TableView) ContentWidth() int {
+	var width int
+	for _, tvc := range tv.columns.items {
+		if tvc.visible && !tvc.frozen {
+			width += tvc.Width()
+		}
+	}
+
+	return width
+}
+
+// FrozenWidth returns the width of the area of the frozen columns, including
+// the row number column, in pixels.
+func (tv *TableView) FrozenWidth() int {
+	width := tv.rowNumberColumnWidth()
+	for _, tvc := range tv.columns.items {
+		if tvc.visible && tvc.frozen {
+			width += tvc.Width()
+		}
+	}
+
+	return width
+}
+
 func (tv *TableView) updateLVSizes() {
+	if tv.lvSizesUpdateDeferred {
+		return
+	}
+
 	cb := tv.ClientBounds()
 
-	var width int
-	for i := tv.columns.Len() - 1; i >= 0; i-- {
-		if col := tv.columns.At(i); col.frozen {
-			width += col.Width()
-		}
+	// The summary row is displayed below both list views, the column groups
+	// above them.
+	top := tv.columnGroupsHeight()
+	cb.Height -= top + tv.summaryRowHeight()
+
+	width := tv.FrozenWidth()
+
+	// Frozen columns are displayed on the right in right to left layout.
+	normalX, frozenX := width, 0
+	if tv.RightToLeftReading() {
+		normalX, frozenX = 0, cb.Width-width
 	}
 
-	win.MoveWindow(tv.hwndNormal, int32(width), 0, int32(cb.Width-width), int32(cb.Height), true)
+	win.MoveWindow(tv.hwndNormal, int32(normalX), int32(top), int32(cb.Width-width), int32(cb.Height), true)
 
 	var sbh int
 	if hasWindowLongBits(tv.hwndNormal, win.GWL_STYLE, win.WS_HSCROLL) {
 		sbh = int(win.GetSystemMetrics(win.SM_CYHSCROLL))
 	}
 
-	win.MoveWindow(tv.hwndFrozen, 0, 0, int32(width), int32(cb.Height-sbh), true)
+	win.MoveWindow(tv.hwndFrozen, int32(frozenX), int32(top), int32(width), int32(cb.Height-sbh), true)
+
+	// Column group headers and header tool tips follow the column headers.
+	tv.invalidateColumnGroups()
+	tv.updateHeaderToolTips()
+
+	tv.reserveVerticalScrollBar()
+
+	if tv.headerFont != nil {
+		// Make the list views lay out their headers again, so the data area
+		// starts below headers that are taller than the default ones.
+		for _, hwnd := range [...]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+			win.SetWindowPos(hwnd, 0, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOZORDER|win.SWP_NOACTIVATE|win.SWP_FRAMECHANGED)
+		}
+	}
 }
@@ -0,0 +1,193 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// benchmarkColumnCount is the number of columns, that the benchmarks add to a
+// TableView. Adding them one by one lays out the TableView once per column, so
+// the difference grows with the count.
+const benchmarkColumnCount = 200
+
+func newBenchmarkColumns() []*TableViewColumn {
+	cols := make([]*TableViewColumn, benchmarkColumnCount)
+	for i := range cols {
+		cols[i] = NewTableViewColumn()
+		cols[i].SetTitle("Column " + strconv.Itoa(i))
+	}
+
+	return cols
+}
+
+func benchmarkTableViewColumns(b *testing.B, add func(tv *TableView, cols []*TableViewColumn) error) {
+	mw, err := NewMainWindow()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mw.Dispose()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tv, err := NewTableView(mw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		cols := newBenchmarkColumns()
+		b.StartTimer()
+
+		if err := add(tv, cols); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		tv.Dispose()
+		b.StartTimer()
+	}
+}
+
+func BenchmarkTableViewColumnsAdd(b *testing.B) {
+	benchmarkTableViewColumns(b, func(tv *TableView, cols []*TableViewColumn) error {
+		for _, tvc := range cols {
+			if err := tv.Columns().Add(tvc); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func BenchmarkTableViewAddColumns(b *testing.B) {
+	benchmarkTableViewColumns(b, func(tv *TableView, cols []*TableViewColumn) error {
+		return tv.AddColumns(cols)
+	})
+}
+
+// addedColumnState is what TestTableViewAddColumns compares for each
+// column.
+type addedColumnState struct {
+	Title   string
+	Visible bool
+	Frozen  bool
+	Width   int
+	Fmt     int32
+	Image   int32
+}
+
+func addedColumnStates(tv *TableView) (states []addedColumnState, displayOrder []string) {
+	for _, tvc := range tv.columns.items {
+		state := addedColumnState{
+			Title:   tvc.Title(),
+			Visible: tvc.Visible(),
+			Frozen:  tvc.Frozen(),
+			Width:   tvc.Width(),
+		}
+
+		if tvc.visible {
+			item := win.HDITEM{Mask: win.HDI_FORMAT | win.HDI_IMAGE}
+			win.SendMessage(tv.headerHwnd(tvc), win.HDM_GETITEM, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(&item)))
+
+			state.Fmt = item.Fmt
+			if item.Fmt&win.HDF_IMAGE != 0 {
+				state.Image = item.IImage
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	for _, tvc := range tv.VisibleColumnsInDisplayOrder() {
+		displayOrder = append(displayOrder, tvc.Title())
+	}
+
+	return
+}
+
+func TestTableViewAddColumns(t *testing.T) {
+	mw, err := NewMainWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mw.Dispose()
+
+	image, err := NewBitmap(Size{16, 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer image.Dispose()
+
+	newColumns := func() []*TableViewColumn {
+		cols := make([]*TableViewColumn, 8)
+		for i := range cols {
+			cols[i] = NewTableViewColumn()
+			cols[i].SetTitle("Column " + strconv.Itoa(i))
+			cols[i].SetWidth(50 + i)
+		}
+
+		cols[1].SetVisible(false)
+		cols[2].SetHeaderImage(image)
+		cols[3].SetAlignment(AlignFar)
+		cols[5].SetHeaderImage(image)
+		cols[6].SetVisible(false)
+
+		return cols
+	}
+
+	newTableView := func(add func(tv *TableView, cols []*TableViewColumn) error) *TableView {
+		tv, err := NewTableView(mw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tv.SetFrozenColumnCount(2); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := add(tv, newColumns()); err != nil {
+			t.Fatal(err)
+		}
+
+		return tv
+	}
+
+	added := newTableView(func(tv *TableView, cols []*TableViewColumn) error {
+		for _, tvc := range cols {
+			if err := tv.Columns().Add(tvc); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	defer added.Dispose()
+
+	batched := newTableView(func(tv *TableView, cols []*TableViewColumn) error {
+		return tv.AddColumns(cols)
+	})
+	defer batched.Dispose()
+
+	if a, b := added.FrozenColumnCount(), batched.FrozenColumnCount(); a != b {
+		t.Errorf("FrozenColumnCount: Columns().Add: %d, AddColumns: %d", a, b)
+	}
+
+	addedStates, addedOrder := addedColumnStates(added)
+	batchedStates, batchedOrder := addedColumnStates(batched)
+
+	if !reflect.DeepEqual(addedStates, batchedStates) {
+		t.Errorf("columns differ:\nColumns().Add: %+v\nAddColumns:    %+v", addedStates, batchedStates)
+	}
+	if !reflect.DeepEqual(addedOrder, batchedOrder) {
+		t.Errorf("display order differs:\nColumns().Add: %v\nAddColumns:    %v", addedOrder, batchedOrder)
+	}
+}
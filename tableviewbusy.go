@@ -0,0 +1,145 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"github.com/lxn/win"
+)
+
+const tableViewBusyOverlayOpacity = 160
+
+// Busy returns if the *TableView is busy, e.g. while its model loads.
+func (tv *TableView) Busy() bool {
+	return tv.busy
+}
+
+// SetBusy sets if the *TableView is busy, e.g. while its model loads or
+// refreshes.
+//
+// A busy *TableView covers its rows with a semi-transparent overlay, that
+// displays the BusyMessage, and does not respond to the mouse or keyboard.
+// Selection and scroll position are kept, and the keyboard focus is restored
+// when the *TableView is no longer busy.
+func (tv *TableView) SetBusy(busy bool) {
+	if busy == tv.busy {
+		return
+	}
+
+	if busy {
+		focus := win.GetFocus()
+		tv.busyHadFocus = focus == tv.hwndFrozen || focus == tv.hwndNormal || focus == tv.hWnd
+	}
+
+	tv.busy = busy
+
+	enabled := tv.Enabled() && !busy
+	win.EnableWindow(tv.hwndFrozen, enabled)
+	win.EnableWindow(tv.hwndNormal, enabled)
+
+	if !busy && tv.busyHadFocus {
+		tv.busyHadFocus = false
+
+		if enabled {
+			win.SetFocus(tv.hwndFrozen)
+		}
+	}
+
+	tv.invalidateListViews()
+}
+
+// BusyMessage returns the text that is displayed while the *TableView is busy.
+func (tv *TableView) BusyMessage() string {
+	return tv.busyMessage
+}
+
+// SetBusyMessage sets the text that is displayed while the *TableView is busy,
+// e.g. "Loading...". By default no text is displayed.
+func (tv *TableView) SetBusyMessage(message string) {
+	if message == tv.busyMessage {
+		return
+	}
+
+	tv.busyMessage = message
+
+	if tv.busy {
+		tv.invalidateListViews()
+	}
+}
+
+func (tv *TableView) invalidateListViews() {
+	win.InvalidateRect(tv.hwndFrozen, nil, true)
+	win.InvalidateRect(tv.hwndNormal, nil, true)
+}
+
+// drawBusyOverlay covers list view hwnd, that has been painted already, with
+// the busy overlay.
+func (tv *TableView) drawBusyOverlay(hwnd win.HWND) {
+	var rc win.RECT
+	if !win.GetClientRect(hwnd, &rc) || rc.Right <= rc.Left || rc.Bottom <= rc.Top {
+		return
+	}
+
+	hdc := win.GetDC(hwnd)
+	if hdc == 0 {
+		return
+	}
+	defer win.ReleaseDC(hwnd, hdc)
+
+	// A single pixel of the overlay color is stretched over the list view.
+	hdcMem := win.CreateCompatibleDC(hdc)
+	if hdcMem == 0 {
+		return
+	}
+	defer win.DeleteDC(hdcMem)
+
+	hBmp := win.CreateCompatibleBitmap(hdc, 1, 1)
+	if hBmp == 0 {
+		return
+	}
+	defer win.DeleteObject(win.HGDIOBJ(hBmp))
+
+	hBmpOld := win.SelectObject(hdcMem, win.HGDIOBJ(hBmp))
+	defer win.SelectObject(hdcMem, hBmpOld)
+
+	if canvas, err := newCanvasFromHDC(hdcMem); err == nil {
+		if brush, err := NewSystemColorBrush(SysColorBtnFace); err == nil {
+			canvas.FillRectangle(brush, Rectangle{0, 0, 1, 1})
+		}
+		canvas.Dispose()
+	}
+
+	win.AlphaBlend(
+		hdc,
+		rc.Left,
+		rc.Top,
+		rc.Right-rc.Left,
+		rc.Bottom-rc.Top,
+		hdcMem,
+		0,
+		0,
+		1,
+		1,
+		win.BLENDFUNCTION{SourceConstantAlpha: tableViewBusyOverlayOpacity})
+
+	// The message is centered in the area of the normal columns.
+	if hwnd != tv.hwndNormal || tv.busyMessage == "" {
+		return
+	}
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	canvas.DrawText(
+		tv.busyMessage,
+		tv.Font(),
+		Color(win.GetSysColor(win.COLOR_BTNTEXT)),
+		rectangleFromRECT(rc),
+		TextCenter|TextVCenter|TextSingleLine|TextEndEllipsis|TextNoPrefix)
+}
@@ -0,0 +1,377 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// CellEditor is the interface that describes how the cells of a TableView
+// column are edited.
+type CellEditor interface {
+	// EditorValues returns the values the user can choose from when editing
+	// the specified cell, or nil to edit the cell as free text.
+	EditorValues(row, col int) []string
+}
+
+type comboCellEditor struct {
+	values []string
+}
+
+// NewComboCellEditor returns a CellEditor that lets the user choose one of
+// values from a drop down list.
+func NewComboCellEditor(values []string) CellEditor {
+	return &comboCellEditor{values: append([]string(nil), values...)}
+}
+
+func (cce *comboCellEditor) EditorValues(row, col int) []string {
+	return cce.values
+}
+
+var tableViewCellEditWndProcPtr = syscall.NewCallback(tableViewCellEditWndProc)
+
+func tableViewCellEditWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
+	tv := (*TableView)(unsafe.Pointer(win.GetWindowLongPtr(hwnd, win.GWLP_USERDATA)))
+
+	origWndProcPtr := tv.cellEditOrigWndProcPtr
+
+	switch msg {
+	case win.WM_GETDLGCODE:
+		return win.DLGC_WANTALLKEYS
+
+	case win.WM_KEYDOWN:
+		switch wp {
+		case win.VK_RETURN:
+			tv.endCellEdit(true)
+			return 0
+
+		case win.VK_ESCAPE:
+			tv.endCellEdit(false)
+			return 0
+		}
+
+	case win.WM_CHAR:
+		if wp == '\r' || wp == 0x1b {
+			// Swallow these to prevent the edit control from beeping.
+			return 0
+		}
+
+	case win.WM_KILLFOCUS:
+		result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+		tv.endCellEdit(true)
+
+		return result
+	}
+
+	return win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+}
+
+// CellEditable returns if the cells of the column at index col can be edited
+// by the user.
+func (tv *TableView) CellEditable(col int) bool {
+	if col < 0 || col >= tv.columns.Len() {
+		return false
+	}
+
+	return tv.columns.items[col].editable
+}
+
+// SetCellEditable sets if the cells of the column at index col can be edited
+// by the user.
+//
+// Editing requires the model to implement CellSetter. It is started by
+// pressing F2 or by double clicking an editable cell. Enter commits the
+// edited value, Escape cancels editing.
+func (tv *TableView) SetCellEditable(col int, editable bool) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tv.columns.items[col].editable = editable
+
+	return nil
+}
+
+// ColumnEditor returns the CellEditor of the column at index col, or nil if
+// its cells are edited as free text.
+func (tv *TableView) ColumnEditor(col int) CellEditor {
+	if col < 0 || col >= tv.columns.Len() {
+		return nil
+	}
+
+	return tv.columns.items[col].editor
+}
+
+// SetColumnEditor sets the CellEditor of the column at index col.
+//
+// If the editor provides values for a cell, it is edited using a drop down
+// list of those values. The chosen value is committed through the CellSetter
+// of the model. Setting an editor makes the column editable, pass nil to edit
+// its cells as free text again.
+func (tv *TableView) SetColumnEditor(col int, editor CellEditor) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tvc := tv.columns.items[col]
+
+	tvc.editor = editor
+	if editor != nil {
+		tvc.editable = true
+	}
+
+	return nil
+}
+
+// CellEdited returns the event that is published after the value of a cell
+// was changed by the user.
+func (tv *TableView) CellEdited() *CellEvent {
+	return tv.cellEditedPublisher.Event()
+}
+
+// EditCell starts editing the cell at row and col.
+//
+// An editor is placed over the cell. The value is committed to the model
+// through its CellSetter implementation.
+func (tv *TableView) EditCell(row, col int) error {
+	if tv.cellSetter == nil {
+		return newError("model does not implement CellSetter")
+	}
+	modelRow := tv.ViewToModelIndex(row)
+	if modelRow < 0 || modelRow >= tv.model.RowCount() {
+		return newError("row out of range")
+	}
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tvc := tv.columns.items[col]
+	if !tvc.visible {
+		return newError("column not visible")
+	}
+
+	if err := tv.endCellEdit(true); err != nil {
+		return err
+	}
+
+	var hwndLV win.HWND
+	if tvc.frozen {
+		hwndLV = tv.hwndFrozen
+	} else {
+		hwndLV = tv.hwndNormal
+	}
+
+	if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_ENSUREVISIBLE, uintptr(row), 0) {
+		return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	}
+	if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_ENSUREVISIBLE, uintptr(row), 0) {
+		return newError("SendMessage(LVM_ENSUREVISIBLE)")
+	}
+
+	subItem := tvc.indexInListView()
+
+	subItemRect := func() (rc win.RECT, err error) {
+		rc.Top = subItem
+		if subItem == 0 {
+			// LVIR_BOUNDS would return the bounds of the whole item.
+			rc.Left = win.LVIR_LABEL
+		} else {
+			rc.Left = win.LVIR_BOUNDS
+		}
+
+		if win.FALSE == win.SendMessage(hwndLV, win.LVM_GETSUBITEMRECT, uintptr(row), uintptr(unsafe.Pointer(&rc))) {
+			err = newError("SendMessage(LVM_GETSUBITEMRECT)")
+		}
+
+		return
+	}
+
+	rc, err := subItemRect()
+	if err != nil {
+		return err
+	}
+
+	// Scroll the cell into view horizontally.
+	if width := windowClientBounds(hwndLV).Width; rc.Left < 0 || int(rc.Right) > width {
+		dx := rc.Left
+		if dx > 0 {
+			dx = int32(mini(int(rc.Right)-width, int(rc.Left)))
+		}
+
+		win.SendMessage(hwndLV, win.LVM_SCROLL, uintptr(dx), 0)
+
+		if rc, err = subItemRect(); err != nil {
+			return err
+		}
+	}
+
+	topLeft := win.POINT{X: rc.Left, Y: rc.Top}
+	if hasWindowLongBits(hwndLV, win.GWL_EXSTYLE, win.WS_EX_LAYOUTRTL) {
+		// In mirrored windows, the left edge is on the right.
+		topLeft.X = rc.Right
+	}
+	win.ClientToScreen(hwndLV, &topLeft)
+	win.ScreenToClient(tv.hWnd, &topLeft)
+
+	var values []string
+	if tvc.editor != nil {
+		values = tvc.editor.EditorValues(modelRow, col)
+	}
+
+	className := "EDIT"
+	height := rc.Bottom - rc.Top
+
+	var style uint32
+	if values != nil {
+		className = "COMBOBOX"
+		style = win.WS_CHILD | win.WS_VSCROLL | win.CBS_DROPDOWNLIST
+
+		// For combo boxes, the height includes the drop down list.
+		height *= int32(mini(len(values), 8) + 1)
+	} else {
+		style = win.WS_CHILD | win.WS_BORDER | win.ES_AUTOHSCROLL
+		switch tvc.alignment {
+		case AlignCenter:
+			style |= win.ES_CENTER
+
+		case AlignFar:
+			style |= win.ES_RIGHT
+		}
+	}
+
+	var text string
+	switch val := tv.model.Value(modelRow, col).(type) {
+	case nil:
+
+	case string:
+		text = val
+
+	default:
+		text = fmt.Sprint(val)
+	}
+
+	hwnd := win.CreateWindowEx(
+		0,
+		syscall.StringToUTF16Ptr(className),
+		syscall.StringToUTF16Ptr(text),
+		style,
+		topLeft.X,
+		topLeft.Y,
+		rc.Right-rc.Left,
+		height,
+		tv.hWnd,
+		0,
+		0,
+		nil)
+	if hwnd == 0 {
+		return lastError("CreateWindowEx")
+	}
+
+	win.SetWindowLongPtr(hwnd, win.GWLP_USERDATA, uintptr(unsafe.Pointer(tv)))
+	tv.cellEditOrigWndProcPtr = win.SetWindowLongPtr(hwnd, win.GWLP_WNDPROC, tableViewCellEditWndProcPtr)
+	if tv.cellEditOrigWndProcPtr == 0 {
+		win.DestroyWindow(hwnd)
+		return lastError("SetWindowLongPtr")
+	}
+
+	tv.hwndCellEdit = hwnd
+	tv.cellEditRow = row
+	tv.cellEditCol = col
+
+	win.SendMessage(hwnd, win.WM_SETFONT, uintptr(tv.Font().handleForDPI(0)), 0)
+
+	if values != nil {
+		for _, value := range values {
+			win.SendMessage(hwnd, win.CB_ADDSTRING, 0, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(value))))
+		}
+
+		index := win.SendMessage(hwnd, win.CB_FINDSTRINGEXACT, ^uintptr(0), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text))))
+		win.SendMessage(hwnd, win.CB_SETCURSEL, index, 0)
+	} else {
+		win.SendMessage(hwnd, win.EM_SETSEL, 0, ^uintptr(0))
+	}
+
+	win.SetWindowPos(hwnd, win.HWND_TOP, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_SHOWWINDOW)
+	win.SetFocus(hwnd)
+
+	if values != nil {
+		win.SendMessage(hwnd, win.CB_SHOWDROPDOWN, win.TRUE, 0)
+	}
+
+	return nil
+}
+
+func (tv *TableView) endCellEdit(commit bool) error {
+	hwnd := tv.hwndCellEdit
+	if hwnd == 0 {
+		return nil
+	}
+
+	// We reset this first, so we don't get here again when the editor loses
+	// focus during destruction.
+	tv.hwndCellEdit = 0
+
+	var text string
+	if commit {
+		text = windowText(hwnd)
+	}
+
+	win.SetWindowLongPtr(hwnd, win.GWLP_WNDPROC, tv.cellEditOrigWndProcPtr)
+	tv.cellEditOrigWndProcPtr = 0
+
+	if win.GetFocus() == hwnd {
+		win.SetFocus(tv.hwndFrozen)
+	}
+
+	win.DestroyWindow(hwnd)
+
+	if !commit || tv.cellSetter == nil {
+		return nil
+	}
+
+	return tv.setCellValue(tv.cellEditRow, tv.cellEditCol, text)
+}
+
+func (tv *TableView) setCellValue(row, col int, value interface{}) error {
+	if err := tv.cellSetter.SetValue(tv.ViewToModelIndex(row), col, value); err != nil {
+		return wrapError(err)
+	}
+
+	if sorter, ok := tv.model.(Sorter); ok && sorter.SortedColumn() == col {
+		if err := sorter.Sort(col, sorter.SortOrder()); err != nil {
+			return err
+		}
+
+		tv.Invalidate()
+	} else {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_UPDATE, uintptr(row), 0) {
+			return newError("SendMessage(LVM_UPDATE)")
+		}
+		if win.FALSE == win.SendMessage(tv.hwndNormal, win.LVM_UPDATE, uintptr(row), 0) {
+			return newError("SendMessage(LVM_UPDATE)")
+		}
+	}
+
+	tv.cellEditedPublisher.Publish(row, col)
+
+	return nil
+}
+
+func (tv *TableView) firstEditableColumn() int {
+	for _, tvc := range tv.VisibleColumnsInDisplayOrder() {
+		if tvc.editable {
+			return tv.columns.Index(tvc)
+		}
+	}
+
+	return -1
+}
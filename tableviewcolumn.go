@@ -7,6 +7,7 @@
 package walk
 
 import (
+	"strings"
 	"syscall"
 	"unsafe"
 
@@ -15,17 +16,104 @@ import (
 
 // TableViewColumn represents a column in a TableView.
 type TableViewColumn struct {
-	tv            *TableView
-	name          string
-	dataMember    string
-	alignment     Alignment1D
-	format        string
-	precision     int
-	title         string
-	titleOverride string
-	width         int
-	visible       bool
-	frozen        bool
+	tv                 *TableView
+	name               string
+	dataMember         string
+	alignment          Alignment1D
+	format             string
+	precision          int
+	title              string
+	titleOverride      string
+	width              int
+	visible            bool
+	frozen             bool
+	editable           bool
+	editor             CellEditor
+	headerCheckBox     bool
+	headerAlignment    Alignment1D
+	headerAlignmentSet bool
+	minWidth           int
+	maxWidth           int
+	wrapText           bool
+	headerImage        interface{}
+	headerToolTip      string
+	filterable         bool
+	numberFormat       *NumberFormat
+	fixedPosition      bool
+	kind               ColumnKind
+	widthFraction      float64
+	defaultWidth       int
+	defaultFraction    float64
+	defaultVisible     bool
+	defaultFrozen      bool
+}
+
+// ColumnKind specifies how the cells of a TableViewColumn display their values.
+type ColumnKind int
+
+const (
+	// ColumnKindText displays the formatted values as text.
+	ColumnKindText ColumnKind = iota
+
+	// ColumnKindProgressBar displays numeric values from 0 to 100 as progress
+	// bars.
+	ColumnKindProgressBar
+)
+
+// NumberFormat specifies how the float32, float64 and *big.Rat values of a
+// TableViewColumn are formatted, beyond their precision.
+type NumberFormat struct {
+	// Grouping specifies if thousands are separated.
+	Grouping bool
+
+	// Percent specifies if values are displayed as a percentage, i.e.
+	// multiplied by 100 and followed by a percent sign.
+	Percent bool
+
+	// Prefix is displayed in front of the number, e.g. a currency symbol.
+	Prefix string
+
+	// Suffix is displayed after the number.
+	Suffix string
+
+	// NegativeInParentheses specifies if negative values are displayed in
+	// parentheses instead of with a minus sign.
+	NegativeInParentheses bool
+}
+
+// formatFloatString formats s, the result of formatting a number with prec
+// decimal places, according to nf.
+//
+// A nil *NumberFormat formats like TableView always did, with grouping.
+func (nf *NumberFormat) formatFloatString(s string, prec int) string {
+	if nf == nil {
+		return formatFloatString(s, prec, true)
+	}
+
+	negative := nf.NegativeInParentheses && strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+
+	s = formatFloatString(s, prec, nf.Grouping)
+	if nf.Percent {
+		s += "%"
+	}
+
+	s = nf.Prefix + s + nf.Suffix
+	if negative {
+		s = "(" + s + ")"
+	}
+
+	return s
+}
+
+func (nf *NumberFormat) percentFactor() float64 {
+	if nf != nil && nf.Percent {
+		return 100
+	}
+
+	return 1
 }
 
 // NewTableViewColumn returns a new TableViewColumn.
@@ -60,6 +148,62 @@ func (tvc *TableViewColumn) SetAlignment(alignment Alignment1D) (err error) {
 	return tvc.update()
 }
 
+// HeaderAlignment returns the alignment of the header text of the
+// TableViewColumn.
+//
+// Unless set explicitly, this is the alignment of the TableViewColumn.
+func (tvc *TableViewColumn) HeaderAlignment() Alignment1D {
+	if !tvc.headerAlignmentSet {
+		return tvc.alignment
+	}
+
+	return tvc.headerAlignment
+}
+
+// SetHeaderAlignment sets the alignment of the header text of the
+// TableViewColumn, independent of the alignment of its cells.
+func (tvc *TableViewColumn) SetHeaderAlignment(alignment Alignment1D) error {
+	tvc.headerAlignment = alignment
+	tvc.headerAlignmentSet = true
+
+	return tvc.applyHeaderAlignment()
+}
+
+func (tvc *TableViewColumn) applyHeaderAlignment() error {
+	if tvc.tv == nil || !tvc.visible || !tvc.headerAlignmentSet {
+		return nil
+	}
+
+	hwndHeader := tvc.tv.headerHwnd(tvc)
+
+	item := win.HDITEM{
+		Mask: win.HDI_FORMAT,
+	}
+
+	iPtr := uintptr(tvc.indexInListView())
+	itemPtr := uintptr(unsafe.Pointer(&item))
+
+	if win.SendMessage(hwndHeader, win.HDM_GETITEM, iPtr, itemPtr) == 0 {
+		return newError("SendMessage(HDM_GETITEM)")
+	}
+
+	item.Fmt &^= win.HDF_JUSTIFYMASK
+
+	switch tvc.headerAlignment {
+	case AlignCenter:
+		item.Fmt |= win.HDF_CENTER
+
+	case AlignFar:
+		item.Fmt |= win.HDF_RIGHT
+	}
+
+	if win.SendMessage(hwndHeader, win.HDM_SETITEM, iPtr, itemPtr) == 0 {
+		return newError("SendMessage(HDM_SETITEM)")
+	}
+
+	return nil
+}
+
 // DataMember returns the data member this TableViewColumn is bound against.
 func (tvc *TableViewColumn) DataMember() string {
 	return tvc.dataMember
@@ -232,10 +376,15 @@ func (tvc *TableViewColumn) SetVisible(visible bool) (err error) {
 	}
 
 	if visible {
-		return tvc.create()
+		err = tvc.create()
+	} else {
+		err = tvc.destroy()
+	}
+	if err != nil {
+		return
 	}
 
-	return tvc.destroy()
+	return tvc.tv.applyFrozenColumnCount()
 }
 
 // Frozen returns if the column is frozen.
@@ -265,7 +414,7 @@ func (tvc *TableViewColumn) SetFrozen(frozen bool) (err error) {
 		}
 
 		if tvc.tv != nil {
-			tvc.tv.hasFrozenColumn = tvc.tv.visibleFrozenColumnCount() > 0
+			tvc.tv.hasFrozenColumn = tvc.tv.visibleFrozenColumnCount() > 0 || tvc.tv.rowNumbersVisible
 			tvc.tv.SetCheckBoxes(checkBoxes)
 			tvc.tv.applyImageList()
 		}
@@ -286,6 +435,174 @@ func (tvc *TableViewColumn) SetFrozen(frozen bool) (err error) {
 	return nil
 }
 
+// MinWidth returns the minimum width of the column in pixels, or 0 if there
+// is no minimum.
+func (tvc *TableViewColumn) MinWidth() int {
+	return tvc.minWidth
+}
+
+// SetMinWidth sets the minimum width of the column in pixels.
+//
+// The user cannot make the column narrower. Pass 0 to remove the constraint.
+func (tvc *TableViewColumn) SetMinWidth(width int) error {
+	tvc.minWidth = width
+
+	return tvc.applyWidthConstraints()
+}
+
+// MaxWidth returns the maximum width of the column in pixels, or 0 if there
+// is no maximum.
+func (tvc *TableViewColumn) MaxWidth() int {
+	return tvc.maxWidth
+}
+
+// SetMaxWidth sets the maximum width of the column in pixels.
+//
+// The user cannot make the column wider. Pass 0 to remove the constraint.
+func (tvc *TableViewColumn) SetMaxWidth(width int) error {
+	tvc.maxWidth = width
+
+	return tvc.applyWidthConstraints()
+}
+
+// WrapText returns if long cell text of the column is wrapped onto multiple
+// lines.
+func (tvc *TableViewColumn) WrapText() bool {
+	return tvc.wrapText
+}
+
+// SetWrapText sets if long cell text of the column is wrapped onto multiple
+// lines, instead of being truncated with an ellipsis.
+//
+// All rows of a TableView have the same height, so use TableView.SetRowHeight
+// or a UniformRowHeighter to make room for the additional lines.
+func (tvc *TableViewColumn) SetWrapText(wrap bool) {
+	if wrap == tvc.wrapText {
+		return
+	}
+
+	tvc.wrapText = wrap
+
+	if tvc.tv != nil {
+		tvc.tv.Invalidate()
+	}
+}
+
+// HeaderImage returns the image displayed in the header of the column.
+func (tvc *TableViewColumn) HeaderImage() interface{} {
+	return tvc.headerImage
+}
+
+// SetHeaderImage sets the image displayed in the header of the column.
+//
+// The image can be a *Bitmap, an *Icon or the name of an image resource. It
+// is displayed in front of the title and together with the sort arrow. A
+// header check box takes the place of the image. Pass nil to remove the
+// image.
+func (tvc *TableViewColumn) SetHeaderImage(image interface{}) {
+	tvc.headerImage = image
+
+	if tvc.tv != nil {
+		tvc.tv.updateHeaderImages()
+	}
+}
+
+// HeaderToolTip returns the text of the tool tip displayed for the header of
+// the column.
+func (tvc *TableViewColumn) HeaderToolTip() string {
+	return tvc.headerToolTip
+}
+
+// SetHeaderToolTip sets the text of the tool tip displayed while the mouse
+// cursor hovers over the header of the column, e.g. the full title, if it is
+// too long to be displayed. Pass an empty string to remove the tool tip.
+//
+// Unlike cell tool tips, header tool tips do not depend on the model.
+func (tvc *TableViewColumn) SetHeaderToolTip(text string) {
+	tvc.headerToolTip = text
+
+	if tvc.tv != nil {
+		tvc.tv.updateHeaderToolTips()
+	}
+}
+
+// saveDefaults remembers the current layout of the column, so ResetState can
+// restore it.
+func (tvc *TableViewColumn) saveDefaults() {
+	tvc.defaultWidth = tvc.Width()
+	tvc.defaultFraction = tvc.widthFraction
+	tvc.defaultVisible = tvc.visible
+	tvc.defaultFrozen = tvc.frozen
+}
+
+// resetToDefaults restores the layout remembered by saveDefaults and removes
+// the title override.
+func (tvc *TableViewColumn) resetToDefaults() error {
+	if err := tvc.SetTitleOverride(""); err != nil {
+		return err
+	}
+	if err := tvc.SetVisible(tvc.defaultVisible); err != nil {
+		return err
+	}
+	if err := tvc.SetFrozen(tvc.defaultFrozen); err != nil {
+		return err
+	}
+
+	tvc.widthFraction = tvc.defaultFraction
+
+	// The user may have resized the column since its width was last set.
+	tvc.width = tvc.Width()
+
+	return tvc.SetWidth(tvc.defaultWidth)
+}
+
+func (tvc *TableViewColumn) constrainedWidth(width int) int {
+	if tvc.maxWidth > 0 && width > tvc.maxWidth {
+		width = tvc.maxWidth
+	}
+	if width < tvc.minWidth {
+		width = tvc.minWidth
+	}
+
+	return width
+}
+
+func (tvc *TableViewColumn) applyWidthConstraints() error {
+	if width := tvc.Width(); width != tvc.constrainedWidth(width) {
+		return tvc.clampWidth(tvc.constrainedWidth(width))
+	}
+
+	return nil
+}
+
+// clampWidth sets width, which the width constraints of the column demand,
+// and publishes TableView.ColumnWidthChanged for it.
+func (tvc *TableViewColumn) clampWidth(width int) error {
+	if tvc.tv == nil || !tvc.visible {
+		tvc.width = width
+		return nil
+	}
+
+	// The user may have resized the column since its width was last set.
+	tvc.width = tvc.Width()
+	if width == tvc.width {
+		return nil
+	}
+
+	// The header notifies us of the change as well, but only while the
+	// width is not being clamped.
+	tvc.tv.clampingColumnWidth = true
+	err := tvc.SetWidth(width)
+	tvc.tv.clampingColumnWidth = false
+	if err != nil {
+		return err
+	}
+
+	tvc.tv.columnWidthChangedPublisher.Publish(tvc.tv.columns.Index(tvc))
+
+	return nil
+}
+
 // Width returns the width of the column in pixels.
 func (tvc *TableViewColumn) Width() int {
 	if tvc.tv == nil || !tvc.visible {
@@ -319,6 +636,9 @@ func (tvc *TableViewColumn) indexInListView() int32 {
 	}
 
 	var idx int32
+	if tvc.frozen {
+		idx = tvc.tv.frozenColumnOffset()
+	}
 
 	for _, c := range tvc.tv.columns.items {
 		if c.frozen != tvc.frozen {
@@ -364,8 +684,9 @@ func (tvc *TableViewColumn) create() error {
 	}
 
 	tvc.tv.updateLVSizes()
+	tvc.tv.updateHeaderImages()
 
-	return nil
+	return tvc.applyHeaderAlignment()
 }
 
 func (tvc *TableViewColumn) destroy() error {
@@ -394,8 +715,9 @@ func (tvc *TableViewColumn) update() error {
 	}
 
 	tvc.tv.updateLVSizes()
+	tvc.tv.updateHeaderImages()
 
-	return nil
+	return tvc.applyHeaderAlignment()
 }
 
 func (tvc *TableViewColumn) getLVCOLUMN() *win.LVCOLUMN {
@@ -422,7 +744,7 @@ func (tvc *TableViewColumn) sendMessage(msg uint32, wp, lp uintptr) uintptr {
 		return 0
 	}
 
-	tvc.tv.hasFrozenColumn = tvc.tv.visibleFrozenColumnCount() > 0
+	tvc.tv.hasFrozenColumn = tvc.tv.visibleFrozenColumnCount() > 0 || tvc.tv.rowNumbersVisible
 	tvc.tv.SetCheckBoxes(tvc.tv.CheckBoxes())
 	tvc.tv.applyImageList()
 
@@ -0,0 +1,95 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// ColumnFilterable returns if the header of the column at index col displays
+// a filter drop down button.
+func (tv *TableView) ColumnFilterable(col int) bool {
+	if col < 0 || col >= tv.columns.Len() {
+		return false
+	}
+
+	return tv.columns.items[col].filterable
+}
+
+// SetColumnFilterable sets if the header of the column at index col displays
+// a filter drop down button.
+//
+// Clicking the button publishes ColumnFilterClicked. The *TableView does not
+// filter by itself, it is up to the application to show a filter UI and to
+// apply the filter using SetRowFilter.
+func (tv *TableView) SetColumnFilterable(col int, filterable bool) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tv.columns.items[col].filterable = filterable
+
+	tv.updateHeaderImages()
+
+	return nil
+}
+
+// ColumnFilterClicked returns the event that is published after the filter
+// drop down button in a column header was clicked.
+//
+// The handler receives the index of the column and the bounds of the button in
+// screen coordinates, e.g. to display a filter UI below it.
+func (tv *TableView) ColumnFilterClicked() *ColumnFilterClickEvent {
+	return tv.columnFilterClickedPublisher.Event()
+}
+
+// ColumnFilterButtonBounds returns the bounds of the filter drop down button
+// in the header of the column at index col, in screen coordinates.
+//
+// An empty Rectangle is returned if the column is not visible or not
+// filterable.
+func (tv *TableView) ColumnFilterButtonBounds(col int) Rectangle {
+	if col < 0 || col >= tv.columns.Len() {
+		return Rectangle{}
+	}
+
+	tvc := tv.columns.items[col]
+	if !tvc.visible || !tvc.filterable {
+		return Rectangle{}
+	}
+
+	hwndHeader := tv.headerHwnd(tvc)
+
+	var rc win.RECT
+	if win.FALSE == win.SendMessage(hwndHeader, win.HDM_GETITEMDROPDOWNRECT, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(&rc))) {
+		return Rectangle{}
+	}
+
+	pts := [2]win.POINT{{rc.Left, rc.Top}, {rc.Right, rc.Bottom}}
+	for i := range pts {
+		win.ClientToScreen(hwndHeader, &pts[i])
+	}
+
+	// Mirrored windows may swap left and right.
+	if pts[0].X > pts[1].X {
+		pts[0].X, pts[1].X = pts[1].X, pts[0].X
+	}
+
+	return rectangleFromRECT(win.RECT{pts[0].X, pts[0].Y, pts[1].X, pts[1].Y})
+}
+
+func (tv *TableView) hasFilterableColumn() bool {
+	for _, tvc := range tv.columns.items {
+		if tvc.filterable {
+			return true
+		}
+	}
+
+	return false
+}
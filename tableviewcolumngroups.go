@@ -0,0 +1,330 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// ColumnGroup describes a header, that is displayed above the headers of a
+// span of columns of a TableView.
+type ColumnGroup struct {
+	Title string
+
+	// FirstColumn and LastColumn are the indexes of the first and the last
+	// column of the group, in the order of the column list.
+	FirstColumn int
+	LastColumn  int
+}
+
+func (cg *ColumnGroup) contains(col int) bool {
+	return col >= cg.FirstColumn && col <= cg.LastColumn
+}
+
+// ColumnGroups returns the column groups of the *TableView.
+func (tv *TableView) ColumnGroups() []ColumnGroup {
+	return append([]ColumnGroup(nil), tv.columnGroups...)
+}
+
+// SetColumnGroups sets the column groups, the headers of which are displayed
+// in a band above the column headers. Pass nil to remove the band.
+//
+// Inserting and removing columns later moves the groups along with their
+// columns. Groups, all columns of which are removed, are removed too.
+//
+// Groups must not overlap. The columns of a group are kept next to each other:
+// they are displayed in a row when the groups are set, and the user can only
+// reorder them within their group.
+func (tv *TableView) SetColumnGroups(groups []ColumnGroup) error {
+	for i, g := range groups {
+		if err := tv.checkColumnIndex(g.FirstColumn); err != nil {
+			return err
+		}
+		if err := tv.checkColumnIndex(g.LastColumn); err != nil {
+			return err
+		}
+		if g.FirstColumn > g.LastColumn {
+			return newError(fmt.Sprintf("column group %q: FirstColumn > LastColumn", g.Title))
+		}
+
+		for _, other := range groups[:i] {
+			if g.FirstColumn <= other.LastColumn && other.FirstColumn <= g.LastColumn {
+				return newError(fmt.Sprintf("column groups %q and %q overlap", other.Title, g.Title))
+			}
+		}
+	}
+
+	tv.columnGroups = append([]ColumnGroup(nil), groups...)
+
+	if err := tv.setColumnDisplayOrder(tv.columnsGroupedInDisplayOrder()); err != nil {
+		return err
+	}
+
+	tv.updateLVSizes()
+	tv.WidgetBase.Invalidate()
+
+	return nil
+}
+
+// columnInserted makes the column groups refer to the same columns as before
+// a column was inserted at index col. A column inserted within a group becomes
+// a member of it.
+func (tv *TableView) columnInserted(col int) {
+	for i := range tv.columnGroups {
+		g := &tv.columnGroups[i]
+
+		if col <= g.FirstColumn {
+			g.FirstColumn++
+			g.LastColumn++
+		} else if col <= g.LastColumn {
+			g.LastColumn++
+		}
+	}
+
+	tv.invalidateColumnGroups()
+}
+
+// columnRemoved makes the column groups refer to the same columns as before
+// the column at index col was removed. Groups without columns are removed.
+func (tv *TableView) columnRemoved(col int) {
+	groups := tv.columnGroups[:0]
+
+	for _, g := range tv.columnGroups {
+		if col < g.FirstColumn {
+			g.FirstColumn--
+			g.LastColumn--
+		} else if col <= g.LastColumn {
+			g.LastColumn--
+		}
+
+		if g.FirstColumn <= g.LastColumn {
+			groups = append(groups, g)
+		}
+	}
+
+	if len(groups) == 0 {
+		groups = nil
+	}
+
+	if len(groups) != len(tv.columnGroups) {
+		// The band may have to be removed.
+		tv.columnGroups = groups
+		tv.updateLVSizes()
+		tv.WidgetBase.Invalidate()
+		return
+	}
+
+	tv.columnGroups = groups
+	tv.invalidateColumnGroups()
+}
+
+// columnGroupOf returns the group of the column at index col, or nil.
+func (tv *TableView) columnGroupOf(col int) *ColumnGroup {
+	for i := range tv.columnGroups {
+		if tv.columnGroups[i].contains(col) {
+			return &tv.columnGroups[i]
+		}
+	}
+
+	return nil
+}
+
+// columnsGroupedInDisplayOrder returns the visible columns in display order,
+// but with the columns of each group moved next to the first of them.
+func (tv *TableView) columnsGroupedInDisplayOrder() []*TableViewColumn {
+	cols := tv.VisibleColumnsInDisplayOrder()
+	grouped := make([]*TableViewColumn, 0, len(cols))
+	done := make(map[*ColumnGroup]bool)
+
+	for _, tvc := range cols {
+		g := tv.columnGroupOf(tv.columns.Index(tvc))
+		if g == nil {
+			grouped = append(grouped, tvc)
+			continue
+		}
+		if done[g] {
+			continue
+		}
+		done[g] = true
+
+		for _, c := range cols {
+			if g.contains(tv.columns.Index(c)) {
+				grouped = append(grouped, c)
+			}
+		}
+	}
+
+	return grouped
+}
+
+// columnGroupsAllowDrop returns if the column at index lvColIdx of list view
+// hwnd may be moved to the position order, without separating the columns of
+// a group.
+func (tv *TableView) columnGroupsAllowDrop(hwnd win.HWND, lvColIdx, order int32) bool {
+	if len(tv.columnGroups) == 0 {
+		return true
+	}
+
+	indices, err := tv.lvColumnOrderArray(hwnd)
+	if err != nil {
+		return false
+	}
+
+	from := lvColumnOrder(indices, lvColIdx)
+	if from == -1 || int(order) >= len(indices) {
+		return true
+	}
+
+	// Simulate the move.
+	moved := append(append([]int32(nil), indices[:from]...), indices[from+1:]...)
+	moved = append(moved[:order], append([]int32{lvColIdx}, moved[order:]...)...)
+
+	frozen := hwnd == tv.hwndFrozen
+
+	for i := range tv.columnGroups {
+		g := &tv.columnGroups[i]
+
+		first, last, count := -1, -1, 0
+		for pos, idx := range moved {
+			if col := tv.fromLVColIdx(frozen, idx); col > -1 && g.contains(col) {
+				if first == -1 {
+					first = pos
+				}
+				last = pos
+				count++
+			}
+		}
+
+		if count > 0 && last-first+1 != count {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (tv *TableView) columnGroupsHeight() int {
+	if len(tv.columnGroups) == 0 {
+		return 0
+	}
+
+	return tv.fixedRowHeight()
+}
+
+func (tv *TableView) columnGroupsBounds() win.RECT {
+	var rc win.RECT
+	win.GetClientRect(tv.hWnd, &rc)
+
+	// The band is displayed right above the column headers.
+	rc.Bottom = rc.Top + int32(tv.columnGroupsHeight())
+
+	return rc
+}
+
+func (tv *TableView) invalidateColumnGroups() {
+	if len(tv.columnGroups) == 0 {
+		return
+	}
+
+	rc := tv.columnGroupsBounds()
+	win.InvalidateRect(tv.hWnd, &rc, false)
+}
+
+func (tv *TableView) drawColumnGroups(hdc win.HDC) {
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	rcBand := tv.columnGroupsBounds()
+	band := rectangleFromRECT(rcBand)
+
+	if brush, err := NewSystemColorBrush(SysColorBtnFace); err == nil {
+		canvas.FillRectangle(brush, band)
+	}
+
+	shadow, _ := NewSystemColorBrush(SysColorBtnShadow)
+	if shadow != nil {
+		canvas.FillRectangle(shadow, Rectangle{band.X, band.Y + band.Height - 1, band.Width, 1})
+	}
+
+	font := tv.Font()
+	color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+
+	for i := range tv.columnGroups {
+		g := &tv.columnGroups[i]
+
+		// A group may be split between frozen and normal columns, so we draw
+		// a segment per list view.
+		for _, hwnd := range [...]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+			left, right, ok := tv.columnGroupSegment(hwnd, g)
+			if !ok {
+				continue
+			}
+
+			segment := Rectangle{left, band.Y, right - left, band.Height - 1}
+
+			if shadow != nil {
+				canvas.FillRectangle(shadow, Rectangle{segment.X + segment.Width - 1, segment.Y, 1, segment.Height})
+			}
+
+			text := segment
+			text.X += 6
+			text.Width -= 12
+			if text.Width <= 0 {
+				continue
+			}
+
+			canvas.DrawText(g.Title, font, color, text, TextCenter|TextVCenter|TextSingleLine|TextEndEllipsis|TextNoPrefix)
+		}
+	}
+}
+
+// columnGroupSegment returns the horizontal extent of the headers of the
+// columns of group g in list view hwnd, in client coordinates of the
+// *TableView.
+func (tv *TableView) columnGroupSegment(hwnd win.HWND, g *ColumnGroup) (left, right int, ok bool) {
+	headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+	frozen := hwnd == tv.hwndFrozen
+
+	for col := maxi(0, g.FirstColumn); col <= g.LastColumn && col < tv.columns.Len(); col++ {
+		tvc := tv.columns.items[col]
+		if !tvc.visible || tvc.frozen != frozen {
+			continue
+		}
+
+		var rcItem win.RECT
+		if 0 == win.SendMessage(headerHwnd, win.HDM_GETITEMRECT, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(&rcItem))) {
+			continue
+		}
+
+		rcItem = tv.rectToClient(headerHwnd, rcItem)
+
+		if !ok || int(rcItem.Left) < left {
+			left = int(rcItem.Left)
+		}
+		if !ok || int(rcItem.Right) > right {
+			right = int(rcItem.Right)
+		}
+		ok = true
+	}
+
+	if !ok {
+		return
+	}
+
+	// Segments must not spill into the other list view or the scroll bar.
+	rcSection := tv.listViewBoundsInClient(hwnd)
+	left = maxi(left, int(rcSection.Left))
+	right = mini(right, int(rcSection.Right))
+
+	return left, right, right > left
+}
@@ -60,6 +60,12 @@ func (l *TableViewColumnList) Contains(item *TableViewColumn) bool {
 // A TableViewColumn cannot be contained in multiple TableViewColumnLists at the
 // same time.
 func (l *TableViewColumnList) Insert(index int, item *TableViewColumn) error {
+	return l.insert(index, item, true)
+}
+
+// insert inserts item at position index. If applyFrozen is false, the caller
+// applies the frozen column count after inserting a batch of columns.
+func (l *TableViewColumnList) insert(index int, item *TableViewColumn, applyFrozen bool) error {
 	if item.tv != nil {
 		return newError("duplicate insert")
 	}
@@ -77,6 +83,14 @@ func (l *TableViewColumnList) Insert(index int, item *TableViewColumn) error {
 	copy(l.items[index+1:], l.items[index:])
 	l.items[index] = item
 
+	l.tv.columnInserted(index)
+
+	item.saveDefaults()
+
+	if item.visible && applyFrozen {
+		return l.tv.applyFrozenColumnCount()
+	}
+
 	return nil
 }
 
@@ -107,6 +121,8 @@ func (l *TableViewColumnList) RemoveAt(index int) error {
 
 	l.items = append(l.items[:index], l.items[index+1:]...)
 
+	l.tv.columnRemoved(index)
+
 	return nil
 }
 
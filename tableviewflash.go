@@ -0,0 +1,165 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"time"
+
+	"github.com/lxn/win"
+)
+
+const tableViewItemFlashInterval = 40 // milliseconds
+
+type tableViewItemFlash struct {
+	id       interface{}
+	color    Color
+	start    time.Time
+	duration time.Duration
+}
+
+// FlashItem briefly highlights the item at index with the specified
+// background color, that fades to the regular background color of the item
+// within duration.
+//
+// Flashing an item again restarts its highlight.
+func (tv *TableView) FlashItem(index int, color Color, duration time.Duration) error {
+	if index < 0 || index >= tv.itemCount() {
+		return newError("index out of range")
+	}
+	if duration <= 0 {
+		return newError("duration must be positive")
+	}
+
+	if tv.itemFlashes == nil {
+		tv.itemFlashes = make(map[int]*tableViewItemFlash)
+	}
+
+	row := tv.ViewToModelIndex(index)
+
+	tv.itemFlashes[row] = &tableViewItemFlash{
+		id:       tv.itemIdentity(row),
+		color:    color,
+		start:    time.Now(),
+		duration: duration,
+	}
+
+	tv.redrawItem(index)
+
+	if 0 == win.SetTimer(tv.hWnd, tableViewItemFlashTimerId, tableViewItemFlashInterval, 0) {
+		return lastError("SetTimer")
+	}
+
+	return nil
+}
+
+// itemFlashColor returns the background color of a flashing item, blended
+// with bg, or bg if the item at model row is not flashing.
+func (tv *TableView) itemFlashColor(row int, bg Color) Color {
+	flash, ok := tv.itemFlashes[row]
+	if !ok {
+		return bg
+	}
+
+	elapsed := time.Since(flash.start)
+	if elapsed >= flash.duration {
+		return bg
+	}
+
+	// The weight of the flash color decreases linearly over time.
+	w := int(256 * (flash.duration - elapsed) / flash.duration)
+
+	blend := func(a, b byte) byte {
+		return byte((int(a)*w + int(b)*(256-w)) / 256)
+	}
+
+	return RGB(
+		blend(flash.color.R(), bg.R()),
+		blend(flash.color.G(), bg.G()),
+		blend(flash.color.B(), bg.B()))
+}
+
+// updateItemFlashes redraws the flashing items and drops the ones that have
+// faded.
+func (tv *TableView) updateItemFlashes() {
+	for row, flash := range tv.itemFlashes {
+		if time.Since(flash.start) >= flash.duration {
+			delete(tv.itemFlashes, row)
+		}
+
+		if index := tv.ModelToViewIndex(row); index > -1 {
+			tv.redrawItem(index)
+		}
+	}
+
+	if len(tv.itemFlashes) > 0 {
+		if 0 == win.SetTimer(tv.hWnd, tableViewItemFlashTimerId, tableViewItemFlashInterval, 0) {
+			lastError("SetTimer")
+		}
+	}
+}
+
+// shiftItemFlashes keeps flashes attached to their rows after count rows were
+// inserted at model row from. A negative count means rows were removed, the
+// flashes of which are canceled.
+func (tv *TableView) shiftItemFlashes(from, count int) {
+	if len(tv.itemFlashes) == 0 {
+		return
+	}
+
+	flashes := make(map[int]*tableViewItemFlash, len(tv.itemFlashes))
+
+	for row, flash := range tv.itemFlashes {
+		switch {
+		case row < from:
+			flashes[row] = flash
+
+		case count < 0 && row < from-count:
+			// The row was removed.
+
+		default:
+			flashes[row+count] = flash
+		}
+	}
+
+	tv.itemFlashes = flashes
+}
+
+// remapItemFlashes keeps flashes attached to their items after the model was
+// sorted. Flashes of items without an identity are canceled.
+func (tv *TableView) remapItemFlashes() {
+	if len(tv.itemFlashes) == 0 {
+		return
+	}
+
+	id2Flash := make(map[interface{}]*tableViewItemFlash, len(tv.itemFlashes))
+	for _, flash := range tv.itemFlashes {
+		if flash.id != nil {
+			id2Flash[flash.id] = flash
+		}
+	}
+
+	tv.itemFlashes = make(map[int]*tableViewItemFlash, len(id2Flash))
+
+	for row, count := 0, tv.model.RowCount(); row < count && len(tv.itemFlashes) < len(id2Flash); row++ {
+		if flash, ok := id2Flash[tv.itemIdentity(row)]; ok {
+			tv.itemFlashes[row] = flash
+		}
+	}
+}
+
+func (tv *TableView) cancelItemFlashes() {
+	tv.itemFlashes = nil
+
+	if tv.hWnd != 0 {
+		win.KillTimer(tv.hWnd, tableViewItemFlashTimerId)
+	}
+}
+
+func (tv *TableView) redrawItem(index int) {
+	win.SendMessage(tv.hwndFrozen, win.LVM_REDRAWITEMS, uintptr(index), uintptr(index))
+	win.SendMessage(tv.hwndNormal, win.LVM_REDRAWITEMS, uintptr(index), uintptr(index))
+}
@@ -0,0 +1,445 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// HeaderCheckBox returns if the header of the column at index col displays a
+// check box.
+func (tv *TableView) HeaderCheckBox(col int) bool {
+	if col < 0 || col >= tv.columns.Len() {
+		return false
+	}
+
+	return tv.columns.items[col].headerCheckBox
+}
+
+// SetHeaderCheckBox sets if the header of the column at index col displays a
+// check box, that checks or unchecks all items when clicked.
+//
+// The check box is checked if all items are checked and indeterminate if only
+// some are. Clicking it checks all items, unless all items are already
+// checked. Header check boxes require the TableView to have an ItemChecker.
+func (tv *TableView) SetHeaderCheckBox(col int, enabled bool) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tv.columns.items[col].headerCheckBox = enabled
+
+	hasHeaderCheckBox := tv.hasHeaderCheckBox()
+
+	for _, hwnd := range [...]win.HWND{tv.frozenHeaderHwnd(), tv.normalHeaderHwnd()} {
+		if err := ensureWindowLongBits(hwnd, win.GWL_STYLE, hdsCheckBoxes, hasHeaderCheckBox); err != nil {
+			return err
+		}
+	}
+
+	if hasHeaderCheckBox && tv.rowChecked == nil {
+		tv.rebuildCheckedRows()
+	}
+
+	tv.updateHeaderImages()
+
+	if !hasHeaderCheckBox {
+		// Stop tracking the check states of the rows.
+		tv.rowChecked = nil
+		tv.checkedCount = 0
+	}
+
+	return nil
+}
+
+// updateHeaderImages applies the header check boxes, header images and filter
+// buttons of the columns.
+func (tv *TableView) updateHeaderImages() {
+	if tv.headerImagesUpdateDeferred {
+		return
+	}
+
+	hIml := tv.headerImageList()
+
+	// Filter buttons must also be removed from the headers.
+	hadFilterButtons := tv.hasFilterButtons
+	tv.hasFilterButtons = tv.hasFilterableColumn()
+
+	if hIml == 0 && !hadFilterButtons && !tv.hasFilterButtons && tv.rowChecked == nil {
+		return
+	}
+
+	for _, hwnd := range [2]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		// The list view may replace the image list of its header.
+		hwndHeader := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+		if hIml != 0 && win.HIMAGELIST(win.SendMessage(hwndHeader, win.HDM_GETIMAGELIST, 0, 0)) != hIml {
+			win.SendMessage(hwndHeader, win.HDM_SETIMAGELIST, 0, uintptr(hIml))
+		}
+	}
+
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible {
+			continue
+		}
+
+		hwndHeader := tv.headerHwnd(tvc)
+
+		item := win.HDITEM{
+			Mask: win.HDI_FORMAT | win.HDI_IMAGE,
+		}
+
+		iPtr := uintptr(tvc.indexInListView())
+		itemPtr := uintptr(unsafe.Pointer(&item))
+
+		if win.SendMessage(hwndHeader, win.HDM_GETITEM, iPtr, itemPtr) == 0 {
+			continue
+		}
+
+		oldFmt, oldImage := item.Fmt, item.IImage
+
+		item.Fmt = item.Fmt&^(win.HDF_CHECKBOX|win.HDF_CHECKED) | tv.headerCheckBoxFormat(tvc)
+
+		if index := tv.headerImageIndex(tvc.headerImage); index > -1 {
+			item.Fmt |= win.HDF_IMAGE
+			item.IImage = index
+		} else {
+			item.Fmt &^= win.HDF_IMAGE
+		}
+
+		if tvc.filterable {
+			item.Fmt |= win.HDF_SPLITBUTTON
+		} else {
+			item.Fmt &^= win.HDF_SPLITBUTTON
+		}
+
+		if item.Fmt == oldFmt && (item.Fmt&win.HDF_IMAGE == 0 || item.IImage == oldImage) {
+			// Setting the item would only make the header repaint.
+			continue
+		}
+
+		win.SendMessage(hwndHeader, win.HDM_SETITEM, iPtr, itemPtr)
+	}
+}
+
+func (tv *TableView) hasHeaderCheckBox() bool {
+	for _, tvc := range tv.columns.items {
+		if tvc.headerCheckBox {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headerCheckBoxFormat returns the header item format flags, that display the
+// header check box of tvc, if any.
+func (tv *TableView) headerCheckBoxFormat(tvc *TableViewColumn) int32 {
+	if !tvc.headerCheckBox || tv.itemChecker == nil {
+		return 0
+	}
+
+	// Headers know no indeterminate check boxes, so those are drawn over
+	// unchecked ones.
+	if tv.headerCheckState == CheckChecked {
+		return win.HDF_CHECKBOX | win.HDF_CHECKED
+	}
+
+	return win.HDF_CHECKBOX
+}
+
+// updateHeaderCheckBoxes updates the header check boxes, if the check states
+// of the items changed, so that none, some or all of them are checked.
+func (tv *TableView) updateHeaderCheckBoxes() {
+	state := tv.itemsCheckState()
+
+	if state == tv.headerCheckState {
+		return
+	}
+
+	tv.headerCheckState = state
+
+	tv.applyHeaderCheckBoxes()
+}
+
+// itemsCheckState returns CheckChecked if all items are checked,
+// CheckIndeterminate if some are and CheckUnchecked otherwise.
+func (tv *TableView) itemsCheckState() CheckState {
+	count := tv.itemCount()
+
+	switch {
+	case tv.checkedCount <= 0 || count == 0:
+		return CheckUnchecked
+
+	case tv.checkedCount >= count:
+		return CheckChecked
+	}
+
+	return CheckIndeterminate
+}
+
+func (tv *TableView) applyHeaderCheckBoxes() {
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible || !tvc.headerCheckBox {
+			continue
+		}
+
+		item := win.HDITEM{
+			Mask: win.HDI_FORMAT,
+		}
+
+		hwndHeader := tv.headerHwnd(tvc)
+		iPtr := uintptr(tvc.indexInListView())
+		itemPtr := uintptr(unsafe.Pointer(&item))
+
+		if win.SendMessage(hwndHeader, win.HDM_GETITEM, iPtr, itemPtr) == 0 {
+			continue
+		}
+
+		item.Fmt = item.Fmt&^(win.HDF_CHECKBOX|win.HDF_CHECKED) | tv.headerCheckBoxFormat(tvc)
+
+		// This also repaints the item, if only the indeterminate state
+		// changed.
+		win.SendMessage(hwndHeader, win.HDM_SETITEM, iPtr, itemPtr)
+	}
+}
+
+// customDrawHeaderCheckBoxes handles the custom draw notifications of header
+// hwndHeader of list view hwnd, to draw indeterminate header check boxes.
+func (tv *TableView) customDrawHeaderCheckBoxes(hwnd, hwndHeader win.HWND, nmcd *win.NMCUSTOMDRAW) uintptr {
+	if tv.headerCheckState != CheckIndeterminate || tv.itemChecker == nil {
+		return win.CDRF_DODEFAULT
+	}
+
+	switch nmcd.DwDrawStage {
+	case win.CDDS_PREPAINT:
+		return win.CDRF_NOTIFYITEMDRAW
+
+	case win.CDDS_ITEMPREPAINT:
+		if col := tv.fromLVColIdx(hwnd == tv.hwndFrozen, int32(nmcd.DwItemSpec)); col > -1 && tv.columns.items[col].headerCheckBox {
+			return win.CDRF_NOTIFYPOSTPAINT
+		}
+
+	case win.CDDS_ITEMPOSTPAINT:
+		hTheme := win.OpenThemeData(hwndHeader, syscall.StringToUTF16Ptr("Button"))
+		if hTheme == 0 {
+			break
+		}
+		defer win.CloseThemeData(hTheme)
+
+		var s win.SIZE
+		if win.S_OK != win.GetThemePartSize(hTheme, nmcd.Hdc, win.BP_CHECKBOX, win.CBS_MIXEDNORMAL, nil, win.TS_TRUE, &s) {
+			break
+		}
+
+		// The header draws its check box in front of the text, at the
+		// bitmap margin.
+		margin := int32(win.SendMessage(hwndHeader, win.HDM_GETBITMAPMARGIN, 0, 0))
+
+		rc := nmcd.Rc
+		rc.Left += margin
+		rc.Top += (rc.Bottom - rc.Top - s.CY) / 2
+		rc.Right = rc.Left + s.CX
+		rc.Bottom = rc.Top + s.CY
+
+		win.DrawThemeBackground(hTheme, nmcd.Hdc, win.BP_CHECKBOX, win.CBS_MIXEDNORMAL, &rc, nil)
+	}
+
+	return win.CDRF_DODEFAULT
+}
+
+// rebuildCheckedRows queries the check states of all rows, e.g. after the
+// model was reset or sorted. The check states are only tracked while a column
+// displays a header check box.
+func (tv *TableView) rebuildCheckedRows() {
+	if tv.rowChecked == nil && !tv.hasHeaderCheckBox() {
+		return
+	}
+
+	tv.rowChecked = make([]bool, 0, tv.itemCount())
+
+	if tv.itemChecker != nil && tv.model != nil {
+		for row, count := 0, tv.model.RowCount(); row < count; row++ {
+			tv.rowChecked = append(tv.rowChecked, tv.itemCheckedAt(row))
+		}
+	}
+
+	tv.recountCheckedRows()
+}
+
+// recountCheckedRows counts the displayed rows, that are checked, e.g. after
+// the row filter changed.
+func (tv *TableView) recountCheckedRows() {
+	if tv.rowChecked == nil {
+		return
+	}
+
+	tv.checkedCount = 0
+
+	if tv.rowFilter == nil {
+		for _, checked := range tv.rowChecked {
+			if checked {
+				tv.checkedCount++
+			}
+		}
+	} else {
+		for _, row := range tv.filteredRows {
+			if row < len(tv.rowChecked) && tv.rowChecked[row] {
+				tv.checkedCount++
+			}
+		}
+	}
+
+	tv.updateHeaderCheckBoxes()
+}
+
+// updateCheckedRows queries the check states of the model rows from through
+// to, which may have changed.
+func (tv *TableView) updateCheckedRows(from, to int) {
+	if tv.rowChecked == nil || tv.itemChecker == nil {
+		return
+	}
+
+	for row := from; row <= to && row < len(tv.rowChecked); row++ {
+		checked := tv.itemCheckedAt(row)
+		if checked == tv.rowChecked[row] {
+			continue
+		}
+
+		tv.rowChecked[row] = checked
+
+		if tv.ModelToViewIndex(row) > -1 {
+			if checked {
+				tv.checkedCount++
+			} else {
+				tv.checkedCount--
+			}
+		}
+	}
+
+	tv.updateHeaderCheckBoxes()
+}
+
+// insertCheckedRows tracks the check states of the model rows from through
+// to, which have just been inserted and filtered.
+func (tv *TableView) insertCheckedRows(from, to int) {
+	if tv.rowChecked == nil || from > len(tv.rowChecked) {
+		return
+	}
+
+	inserted := make([]bool, 1+to-from)
+	tv.rowChecked = append(tv.rowChecked[:from], append(inserted, tv.rowChecked[from:]...)...)
+
+	tv.updateCheckedRows(from, to)
+}
+
+// removeCheckedRows stops tracking the check states of the model rows from
+// through to, which have just been removed. It must be called before the row
+// filter is applied again.
+func (tv *TableView) removeCheckedRows(from, to int) {
+	if tv.rowChecked == nil || to >= len(tv.rowChecked) {
+		return
+	}
+
+	for row := from; row <= to; row++ {
+		if tv.rowChecked[row] && tv.ModelToViewIndex(row) > -1 {
+			tv.checkedCount--
+		}
+	}
+
+	tv.rowChecked = append(tv.rowChecked[:from], tv.rowChecked[to+1:]...)
+}
+
+// refilterCheckedRow counts model row row as displayed or not, after the row
+// filter accepted or rejected it again.
+func (tv *TableView) refilterCheckedRow(row int, displayed bool) {
+	if tv.rowChecked == nil || row >= len(tv.rowChecked) || !tv.rowChecked[row] {
+		return
+	}
+
+	if displayed {
+		tv.checkedCount++
+	} else {
+		tv.checkedCount--
+	}
+}
+
+func (tv *TableView) itemCheckedAt(row int) bool {
+	if tsic := tv.triStateItemChecker; tsic != nil {
+		return tsic.CheckState(row) == CheckChecked
+	}
+
+	return tv.itemChecker.Checked(row)
+}
+
+// toggleAllItemsChecked checks all items, unless all are checked already, in
+// which case it unchecks them. Items are checked if only some of them are.
+func (tv *TableView) toggleAllItemsChecked() error {
+	if tv.itemChecker == nil {
+		return nil
+	}
+
+	checked := tv.headerCheckState != CheckChecked
+
+	var changed []int
+
+	for i, count := 0, tv.itemCount(); i < count; i++ {
+		row := tv.ViewToModelIndex(i)
+
+		if tv.itemCheckedAt(row) == checked {
+			continue
+		}
+
+		if tsic := tv.triStateItemChecker; tsic != nil {
+			state := CheckUnchecked
+			if checked {
+				state = CheckChecked
+			}
+
+			if err := tsic.SetCheckState(row, state); err != nil {
+				return wrapError(err)
+			}
+		} else if err := tv.itemChecker.SetChecked(row, checked); err != nil {
+			return wrapError(err)
+		}
+
+		if tv.rowChecked != nil && row < len(tv.rowChecked) {
+			tv.rowChecked[row] = checked
+			if checked {
+				tv.checkedCount++
+			} else {
+				tv.checkedCount--
+			}
+		}
+
+		changed = append(changed, i)
+	}
+
+	tv.Invalidate()
+
+	// The header may have toggled its check box on its own, so make sure it
+	// displays the state of the items.
+	tv.headerCheckState = tv.itemsCheckState()
+	tv.applyHeaderCheckBoxes()
+
+	for _, i := range changed {
+		tv.itemCheckedChangedPublisher.Publish(i)
+	}
+
+	return nil
+}
+
+func (tv *TableView) headerHwnd(tvc *TableViewColumn) win.HWND {
+	hwnd := tv.hwndNormal
+	if tvc.frozen {
+		hwnd = tv.hwndFrozen
+	}
+
+	return win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"github.com/lxn/win"
+)
+
+// headerImageList returns the image list of the headers, or 0 if they display
+// no images.
+func (tv *TableView) headerImageList() win.HIMAGELIST {
+	if tv.hImlHeader == 0 {
+		var hasImage bool
+		for _, tvc := range tv.columns.items {
+			if tvc.headerImage != nil {
+				hasImage = true
+				break
+			}
+		}
+
+		if !hasImage {
+			return 0
+		}
+
+		// Small icons are 16x16 pixels at 96 DPI.
+		w, h := int32(16*screenDPIX/96), int32(16*screenDPIY/96)
+
+		if tv.hImlHeader = win.ImageList_Create(w, h, win.ILC_MASK|win.ILC_COLOR32, 8, 8); tv.hImlHeader == 0 {
+			newError("ImageList_Create failed")
+			return 0
+		}
+	}
+
+	return tv.hImlHeader
+}
+
+// headerImageIndex returns the index of image in the header image list, adding
+// it if required, or -1 if image is nil or cannot be added.
+func (tv *TableView) headerImageIndex(image interface{}) int32 {
+	if image == nil {
+		return -1
+	}
+
+	hIml := tv.headerImageList()
+	if hIml == 0 {
+		return -1
+	}
+
+	if hIml != tv.headerImlImages {
+		// The images have to be added to the new image list again.
+		tv.headerImlImages = hIml
+		tv.headerImageUintptr2Index = make(map[uintptr]int32)
+	}
+
+	return imageIndexAddIfNotExists(image, hIml, tv.headerImageUintptr2Index)
+}
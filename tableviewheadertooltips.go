@@ -0,0 +1,89 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// updateHeaderToolTips registers the header tool tips of the columns again, as
+// the header items may have been resized, reordered, added or removed.
+func (tv *TableView) updateHeaderToolTips() {
+	if tv.headerToolTip == nil {
+		if !tv.hasHeaderToolTips() {
+			return
+		}
+
+		tt, err := newToolTip(win.TTS_NOPREFIX)
+		if err != nil {
+			return
+		}
+
+		// Header tool tips contain long titles, so we allow for more than a
+		// few words per line, at any DPI.
+		tt.SendMessage(win.TTM_SETMAXTIPWIDTH, 0, uintptr(500*screenDPIX/96))
+
+		tv.headerToolTip = tt
+	}
+
+	tt := tv.headerToolTip
+
+	for i := range tv.headerToolTipTools {
+		tt.SendMessage(win.TTM_DELTOOL, 0, uintptr(unsafe.Pointer(&tv.headerToolTipTools[i])))
+	}
+	tv.headerToolTipTools = tv.headerToolTipTools[:0]
+
+	for i, tvc := range tv.columns.items {
+		if !tvc.visible || tvc.headerToolTip == "" {
+			continue
+		}
+
+		hwnd := tv.hwndNormal
+		if tvc.frozen {
+			hwnd = tv.hwndFrozen
+		}
+		headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+
+		// Tools are identified by the column index, so the one of a column
+		// is found again regardless of its header.
+		var ti win.TOOLINFO
+		ti.CbSize = uint32(unsafe.Sizeof(ti))
+		ti.UFlags = win.TTF_SUBCLASS
+		ti.Hwnd = headerHwnd
+		ti.UId = uintptr(i)
+
+		// The item rectangles are in physical pixels, so they are correct at
+		// any DPI.
+		if 0 == win.SendMessage(headerHwnd, win.HDM_GETITEMRECT, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(&ti.Rect))) {
+			continue
+		}
+
+		ti.LpszText = syscall.StringToUTF16Ptr(tvc.headerToolTip)
+
+		if win.FALSE == tt.SendMessage(win.TTM_ADDTOOL, 0, uintptr(unsafe.Pointer(&ti))) {
+			continue
+		}
+
+		// The tool tip keeps a copy of the text.
+		ti.LpszText = nil
+
+		tv.headerToolTipTools = append(tv.headerToolTipTools, ti)
+	}
+}
+
+func (tv *TableView) hasHeaderToolTips() bool {
+	for _, tvc := range tv.columns.items {
+		if tvc.headerToolTip != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"github.com/lxn/win"
+)
+
+// MarqueeSelectionEnabled returns if the user can select rows by dragging a
+// selection rectangle.
+func (tv *TableView) MarqueeSelectionEnabled() bool {
+	return tv.marqueeSelection
+}
+
+// SetMarqueeSelectionEnabled sets if the user can select rows by dragging a
+// selection rectangle, starting in an empty area of the *TableView.
+//
+// When the mouse button is released, the rows intersecting the rectangle are
+// selected. If the Ctrl key is held down when the drag starts, they are added
+// to the current selection. Pressing Esc cancels the drag.
+//
+// Marquee selection is only available in multi selection mode.
+func (tv *TableView) SetMarqueeSelectionEnabled(enabled bool) {
+	tv.marqueeSelection = enabled
+
+	if !enabled {
+		tv.endMarquee(false)
+	}
+}
+
+func (tv *TableView) beginMarquee(hwnd win.HWND, pt win.POINT) {
+	tv.endCellEdit(true)
+
+	win.SetFocus(tv.hwndFrozen)
+
+	// Like a click into empty space, this clears the selection, unless Ctrl is
+	// held down. tv.publishNextSelClear has been set, so the change is
+	// published.
+	if ControlDown() {
+		tv.marqueeBaseIndexes = tv.SelectedIndexes()
+	} else {
+		tv.marqueeBaseIndexes = nil
+		tv.SetSelectedIndexes(nil)
+	}
+
+	tv.marqueeHwnd = hwnd
+	tv.marqueeAnchor = pt
+	tv.marqueeRect = win.RECT{pt.X, pt.Y, pt.X, pt.Y}
+
+	win.SetCapture(hwnd)
+}
+
+func (tv *TableView) cancelMarqueeOnCaptureLoss() {
+	// Someone else took the capture, so we must not release it.
+	tv.drawMarquee()
+
+	tv.marqueeHwnd = 0
+	tv.marqueeBaseIndexes = nil
+}
+
+func (tv *TableView) updateMarquee() {
+	hwnd := tv.marqueeHwnd
+
+	var pt win.POINT
+	if !win.GetCursorPos(&pt) || !win.ScreenToClient(hwnd, &pt) {
+		return
+	}
+
+	var rcClient win.RECT
+	win.GetClientRect(hwnd, &rcClient)
+
+	rc := win.RECT{
+		Left:   int32(mini(int(tv.marqueeAnchor.X), int(pt.X))),
+		Top:    int32(mini(int(tv.marqueeAnchor.Y), int(pt.Y))),
+		Right:  int32(maxi(int(tv.marqueeAnchor.X), int(pt.X))),
+		Bottom: int32(maxi(int(tv.marqueeAnchor.Y), int(pt.Y))),
+	}
+	rc.Left = int32(maxi(int(rc.Left), int(rcClient.Left)))
+	rc.Top = int32(maxi(int(rc.Top), int(rcClient.Top)))
+	rc.Right = int32(mini(int(rc.Right), int(rcClient.Right)))
+	rc.Bottom = int32(mini(int(rc.Bottom), int(rcClient.Bottom)))
+
+	if rc == tv.marqueeRect {
+		return
+	}
+
+	// The rectangle is drawn using XOR, so drawing it again erases it.
+	tv.drawMarquee()
+	tv.marqueeRect = rc
+	tv.drawMarquee()
+}
+
+func (tv *TableView) endMarquee(apply bool) error {
+	hwnd := tv.marqueeHwnd
+	if hwnd == 0 {
+		return nil
+	}
+
+	tv.drawMarquee()
+
+	// We reset this first, so we don't get here again when we lose the
+	// capture.
+	tv.marqueeHwnd = 0
+
+	win.ReleaseCapture()
+
+	base := tv.marqueeBaseIndexes
+	tv.marqueeBaseIndexes = nil
+
+	rc := tv.marqueeRect
+
+	// Tiny rectangles are clicks rather than drags.
+	if !apply ||
+		int(rc.Right-rc.Left) < int(win.GetSystemMetrics(win.SM_CXDRAG)) &&
+			int(rc.Bottom-rc.Top) < int(win.GetSystemMetrics(win.SM_CYDRAG)) {
+
+		return nil
+	}
+
+	bounds := rectangleFromRECT(tv.rectToClient(hwnd, rc))
+
+	indexes := append([]int(nil), base...)
+	selected := make(map[int]bool, len(base))
+	for _, i := range base {
+		selected[i] = true
+	}
+
+	first := tv.TopIndex()
+	last := mini(tv.itemCount()-1, first+int(win.SendMessage(hwnd, win.LVM_GETCOUNTPERPAGE, 0, 0)))
+
+	for i := first; i <= last; i++ {
+		if selected[i] || !tv.rowSelectable(i) {
+			continue
+		}
+
+		ib := tv.ItemBounds(i)
+		if ib.Width == 0 || ib.Height == 0 {
+			continue
+		}
+
+		if ib.X < bounds.X+bounds.Width && bounds.X < ib.X+ib.Width &&
+			ib.Y < bounds.Y+bounds.Height && bounds.Y < ib.Y+ib.Height {
+
+			indexes = append(indexes, i)
+		}
+	}
+
+	return tv.SetSelectedIndexes(indexes)
+}
+
+func (tv *TableView) drawMarquee() {
+	hwnd := tv.marqueeHwnd
+	if hwnd == 0 || tv.marqueeRect.Right <= tv.marqueeRect.Left || tv.marqueeRect.Bottom <= tv.marqueeRect.Top {
+		return
+	}
+
+	hdc := win.GetDC(hwnd)
+	if hdc == 0 {
+		return
+	}
+	defer win.ReleaseDC(hwnd, hdc)
+
+	win.DrawFocusRect(hdc, &tv.marqueeRect)
+}
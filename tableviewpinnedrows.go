@@ -0,0 +1,310 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"strconv"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// PinnedRowProvider returns the PinnedRowProvider of the *TableView, or nil if
+// no rows are pinned.
+func (tv *TableView) PinnedRowProvider() PinnedRowProvider {
+	return tv.pinnedRowProvider
+}
+
+// SetPinnedRowProvider sets the PinnedRowProvider, that decides which rows of
+// the model are pinned. Pass nil to unpin all rows.
+//
+// Pinned rows are displayed in a band below the column headers, that does not
+// scroll vertically. They keep their regular position among the other rows
+// too, so row indexes are not affected by pinning. Clicking a pinned row makes
+// it the current item.
+//
+// Pinned rows that are hidden by the row filter are not displayed.
+func (tv *TableView) SetPinnedRowProvider(provider PinnedRowProvider) {
+	tv.pinnedRowProvider = provider
+
+	tv.updatePinnedRows()
+}
+
+func (tv *TableView) frozenHeaderHwnd() win.HWND {
+	return win.HWND(win.SendMessage(tv.hwndFrozen, win.LVM_GETHEADER, 0, 0))
+}
+
+func (tv *TableView) normalHeaderHwnd() win.HWND {
+	return win.HWND(win.SendMessage(tv.hwndNormal, win.LVM_GETHEADER, 0, 0))
+}
+
+func tableViewHeaderWndProc(hwnd win.HWND, msg uint32, wp, lp uintptr) uintptr {
+	hwndLV := win.GetParent(hwnd)
+
+	tv, ok := windowFromHandle(win.GetParent(hwndLV)).(*TableView)
+	if !ok {
+		return 0
+	}
+
+	origWndProcPtr := tv.normalHeaderOrigWndProcPtr
+	if hwndLV == tv.hwndFrozen {
+		origWndProcPtr = tv.frozenHeaderOrigWndProcPtr
+	}
+
+	result := win.CallWindowProc(origWndProcPtr, hwnd, msg, wp, lp)
+
+	if msg == win.HDM_LAYOUT && result != 0 {
+		// The list view displays its items below the rectangle, that the
+		// header leaves, so this makes room for the pinned rows.
+		hdl := (*win.HDLAYOUT)(unsafe.Pointer(lp))
+		hdl.Prc.Top += int32(tv.pinnedRowsHeight())
+	}
+
+	return result
+}
+
+func (tv *TableView) pinnedRowsHeight() int {
+	return len(tv.pinnedRows) * tv.fixedRowHeight()
+}
+
+// pinnedRowsBounds returns the bounds of the pinned rows band in list view
+// hwnd, in its client coordinates.
+func (tv *TableView) pinnedRowsBounds(hwnd win.HWND) win.RECT {
+	var rc win.RECT
+	win.GetClientRect(hwnd, &rc)
+
+	// The band is displayed right below the column headers.
+	if headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0)); headerHwnd != 0 && win.IsWindowVisible(headerHwnd) {
+		var rcHeader win.RECT
+		win.GetWindowRect(headerHwnd, &rcHeader)
+
+		pt := win.POINT{rcHeader.Left, rcHeader.Bottom}
+		win.ScreenToClient(hwnd, &pt)
+
+		rc.Top = pt.Y
+	}
+
+	rc.Bottom = rc.Top + int32(tv.pinnedRowsHeight())
+
+	return rc
+}
+
+// updatePinnedRows queries the pinned rows again, as the model or the row
+// filter may have changed.
+func (tv *TableView) updatePinnedRows() {
+	tv.pinnedRows = tv.pinnedRows[:0]
+
+	if tv.pinnedRowProvider != nil && tv.model != nil {
+		rowCount := tv.model.RowCount()
+
+		for _, row := range tv.pinnedRowProvider.PinnedRows() {
+			if row >= 0 && row < rowCount && tv.ModelToViewIndex(row) > -1 {
+				tv.pinnedRows = append(tv.pinnedRows, row)
+			}
+		}
+	}
+
+	if tv.pinnedRowsHeight() != tv.pinnedRowsLayoutHeight {
+		tv.layoutPinnedRows()
+		return
+	}
+
+	tv.invalidatePinnedRows()
+}
+
+// layoutPinnedRows makes the list views lay out their headers again, so the
+// space reserved for the pinned rows matches their number and height.
+func (tv *TableView) layoutPinnedRows() {
+	tv.pinnedRowsLayoutHeight = tv.pinnedRowsHeight()
+
+	for _, hwnd := range [...]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		var rc win.RECT
+		win.GetClientRect(hwnd, &rc)
+
+		// A list view lays out its header whenever it is resized.
+		win.SendMessage(hwnd, win.WM_SIZE, 0, uintptr(win.MAKELONG(uint16(rc.Right), uint16(rc.Bottom))))
+		win.InvalidateRect(hwnd, nil, true)
+	}
+}
+
+func (tv *TableView) invalidatePinnedRows() {
+	if len(tv.pinnedRows) == 0 {
+		return
+	}
+
+	for _, hwnd := range [...]win.HWND{tv.hwndFrozen, tv.hwndNormal} {
+		rc := tv.pinnedRowsBounds(hwnd)
+		win.InvalidateRect(hwnd, &rc, false)
+	}
+}
+
+// pinnedRowAt returns the model index of the pinned row at y in client
+// coordinates of list view hwnd, or -1 if there is none.
+func (tv *TableView) pinnedRowAt(hwnd win.HWND, y int) int {
+	if len(tv.pinnedRows) == 0 {
+		return -1
+	}
+
+	rc := tv.pinnedRowsBounds(hwnd)
+	if y < int(rc.Top) || y >= int(rc.Bottom) {
+		return -1
+	}
+
+	if i := (y - int(rc.Top)) / tv.fixedRowHeight(); i < len(tv.pinnedRows) {
+		return tv.pinnedRows[i]
+	}
+
+	return -1
+}
+
+// drawPinnedRows draws the cells of the pinned rows, that belong to list view
+// hwnd, which has been painted already. Like the list view, it draws check
+// boxes and images in front of the text.
+func (tv *TableView) drawPinnedRows(hwnd win.HWND) {
+	hdc := win.GetDC(hwnd)
+	if hdc == 0 {
+		return
+	}
+	defer win.ReleaseDC(hwnd, hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+	lvColCount := int32(win.SendMessage(headerHwnd, win.HDM_GETITEMCOUNT, 0, 0))
+	frozen := hwnd == tv.hwndFrozen
+
+	var hImlState win.HIMAGELIST
+	if tv.itemChecker != nil && tv.CheckBoxes() {
+		hImlState = win.HIMAGELIST(win.SendMessage(hwnd, lvmGetImageList, win.LVSIL_STATE, 0))
+	}
+	displaysImages := tv.displaysImages(hwnd)
+	iconSize := win.GetSystemMetrics(win.SM_CXSMICON)
+
+	rcBand := tv.pinnedRowsBounds(hwnd)
+	height := int32(tv.fixedRowHeight())
+
+	for i, row := range tv.pinnedRows {
+		rc := rcBand
+		rc.Top += int32(i) * height
+		rc.Bottom = rc.Top + height
+
+		index := tv.ModelToViewIndex(row)
+
+		var style CellStyle
+		style.row = row
+		style.col = -1
+		style.bounds = rectangleFromRECT(rc)
+
+		if tv.alternatingRowBGColor != 0 && index%2 == 1 {
+			style.BackgroundColor = tv.alternatingRowBGColor
+		} else {
+			style.BackgroundColor = defaultTVRowBGColor
+		}
+
+		// Like in the list views, a styler may override the background
+		// color for the whole row.
+		if tv.styler != nil {
+			style.TextColor = RGB(0, 0, 0)
+
+			tv.styler.StyleCell(&style)
+		}
+
+		rowBGColor := style.BackgroundColor
+
+		textColor := RGB(0, 0, 0)
+		selected := tv.IsRowSelected(index)
+		if selected {
+			rowBGColor, textColor = tv.selectionColors()
+		}
+
+		if brush, err := NewSolidColorBrush(rowBGColor); err == nil {
+			canvas.FillRectangle(brush, style.bounds)
+			brush.Dispose()
+		}
+
+		for lvColIdx := int32(0); lvColIdx < lvColCount; lvColIdx++ {
+			col := tv.fromLVColIdx(frozen, lvColIdx)
+			rowNumber := tv.isRowNumberColumn(hwnd, lvColIdx)
+			if col == -1 && !rowNumber {
+				continue
+			}
+
+			var rcItem win.RECT
+			if 0 == win.SendMessage(headerHwnd, win.HDM_GETITEMRECT, uintptr(lvColIdx), uintptr(unsafe.Pointer(&rcItem))) {
+				continue
+			}
+
+			// The header scrolls horizontally along with the items.
+			pts := [2]win.POINT{{rcItem.Left, 0}, {rcItem.Right, 0}}
+			for j := range pts {
+				win.ClientToScreen(headerHwnd, &pts[j])
+				win.ScreenToClient(hwnd, &pts[j])
+			}
+			left, right := pts[0].X, pts[1].X
+			if left > right {
+				left, right = right, left
+			}
+
+			x := left + 2
+			y := rc.Top + (height-iconSize)/2
+
+			if lvColIdx == 0 && hImlState != 0 {
+				win.ImageList_DrawEx(hImlState, int32(tv.checkStateImage(row))-1, hdc, x, y, 0, 0, win.CLR_NONE, win.CLR_NONE, win.ILD_NORMAL)
+				x += iconSize + 2
+			}
+
+			if displaysImages && (tv.cellImageProvider != nil || lvColIdx == 0) {
+				if image, ok := tv.cellImageIndex(row, col, lvColIdx); ok {
+					win.ImageList_DrawEx(tv.hIml, image, hdc, x, y, 0, 0, win.CLR_NONE, win.CLR_NONE, win.ILD_TRANSPARENT)
+					x += iconSize + 2
+				}
+			}
+
+			cell := Rectangle{int(x) + 4, int(rc.Top), int(right-x) - 10, int(height)}
+			if cell.Width <= 0 {
+				continue
+			}
+
+			if rowNumber {
+				canvas.DrawText(strconv.Itoa(index+1), tv.Font(), textColor, cell, TextRight|TextVCenter|TextSingleLine|TextNoPrefix)
+				continue
+			}
+
+			tvc := tv.columns.items[col]
+
+			color, font := textColor, tv.Font()
+
+			if tv.styler != nil && !selected {
+				style.col = col
+				style.bounds = cell
+				style.BackgroundColor = rowBGColor
+				style.TextColor = textColor
+				style.Font = nil
+				style.Image = nil
+
+				tv.styler.StyleCell(&style)
+
+				color = style.TextColor
+				if style.Font != nil {
+					font = style.Font
+				}
+			}
+
+			canvas.DrawText(tv.displayedCellText(row, col), font, color, cell, tvc.fixedRowTextFormat())
+		}
+	}
+
+	// Separate the pinned rows from the other rows.
+	if brush, err := NewSystemColorBrush(SysColorBtnShadow); err == nil {
+		bounds := rectangleFromRECT(rcBand)
+		canvas.FillRectangle(brush, Rectangle{bounds.X, bounds.Y + bounds.Height - 1, bounds.Width, 1})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// ColumnKind returns how the cells of the column at index col display their
+// values.
+func (tv *TableView) ColumnKind(col int) ColumnKind {
+	if col < 0 || col >= tv.columns.Len() {
+		return ColumnKindText
+	}
+
+	return tv.columns.items[col].kind
+}
+
+// SetColumnKind sets how the cells of the column at index col display their
+// values.
+//
+// The cells of a ColumnKindProgressBar column display numeric values, which
+// are expected to range from 0 to 100, as progress bars. The values are still
+// sorted like numbers.
+func (tv *TableView) SetColumnKind(col int, kind ColumnKind) error {
+	if err := tv.checkColumnIndex(col); err != nil {
+		return err
+	}
+
+	tv.columns.items[col].kind = kind
+
+	return tv.Invalidate()
+}
+
+// progressValue returns value as a number from 0 to 100, if it is numeric.
+func progressValue(value interface{}) (float64, bool) {
+	var f float64
+
+	switch val := value.(type) {
+	case *big.Rat:
+		if val == nil {
+			return 0, false
+		}
+		f, _ = val.Float64()
+
+	default:
+		v := reflect.ValueOf(value)
+
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(v.Int())
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			f = float64(v.Uint())
+
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+
+		default:
+			return 0, false
+		}
+	}
+
+	if math.IsNaN(f) {
+		return 0, false
+	}
+
+	return math.Max(0, math.Min(100, f)), true
+}
+
+// drawProgressBarCell draws the cell of model row and col as a progress bar,
+// with the background color bg and text color text.
+func (tv *TableView) drawProgressBarCell(hwnd win.HWND, nmlvcd *win.NMLVCUSTOMDRAW, row, col int, bg, text Color) {
+	rc := win.RECT{Top: nmlvcd.ISubItem, Left: win.LVIR_BOUNDS}
+	if 0 == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, nmlvcd.Nmcd.DwItemSpec, uintptr(unsafe.Pointer(&rc))) {
+		return
+	}
+
+	canvas, err := newCanvasFromHDC(nmlvcd.Nmcd.Hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	cell := rectangleFromRECT(rc)
+	if tv.Gridlines() {
+		// Leave the gridlines intact.
+		cell.Width--
+		cell.Height--
+	}
+
+	if brush, err := NewSolidColorBrush(bg); err == nil {
+		canvas.FillRectangle(brush, cell)
+		brush.Dispose()
+	}
+
+	var value interface{}
+	if av := tv.asyncValuer; av != nil {
+		var ready bool
+		if value, ready = av.ValueAsync(row, col); !ready {
+			return
+		}
+	} else {
+		value = tv.model.Value(row, col)
+	}
+
+	percent, ok := progressValue(value)
+	if !ok {
+		return
+	}
+
+	bar := Rectangle{cell.X + 4, cell.Y + 3, cell.Width - 8, cell.Height - 6}
+	if bar.Width <= 2 || bar.Height <= 2 {
+		return
+	}
+
+	if pen, err := NewCosmeticPen(PenSolid, Color(win.GetSysColor(win.COLOR_BTNSHADOW))); err == nil {
+		canvas.DrawRectangle(pen, bar)
+		pen.Dispose()
+	}
+
+	fill := Rectangle{bar.X + 1, bar.Y + 1, int(float64(bar.Width-2) * percent / 100), bar.Height - 2}
+	if fill.Width > 0 {
+		if brush, err := NewSystemColorBrush(SysColorHighlight); err == nil {
+			canvas.FillRectangle(brush, fill)
+		}
+	}
+
+	canvas.DrawText(
+		strconv.Itoa(int(percent))+"%",
+		tv.Font(),
+		text,
+		bar,
+		TextCenter|TextVCenter|TextSingleLine|TextNoPrefix)
+}
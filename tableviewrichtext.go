@@ -0,0 +1,179 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// HighlightTextRuns returns text as runs for a RichTextProvider, where all
+// occurrences of substr are displayed in bold, on a background of color.
+//
+// Matching ignores case. If substr is empty, a single plain run is returned.
+func HighlightTextRuns(text, substr string, color Color) []TextRun {
+	if substr == "" {
+		return []TextRun{{Text: text}}
+	}
+
+	haystack, needle := strings.ToLower(text), strings.ToLower(substr)
+	if len(haystack) != len(text) || len(needle) != len(substr) {
+		// Changing the case changed byte offsets, so we match exactly.
+		haystack, needle = text, substr
+	}
+
+	var runs []TextRun
+
+	for {
+		i := strings.Index(haystack, needle)
+		if i == -1 {
+			break
+		}
+
+		if i > 0 {
+			runs = append(runs, TextRun{Text: text[:i]})
+		}
+		runs = append(runs, TextRun{Text: text[i : i+len(needle)], BackgroundColor: color, Bold: true})
+
+		text, haystack = text[i+len(needle):], haystack[i+len(needle):]
+	}
+
+	if text != "" || len(runs) == 0 {
+		runs = append(runs, TextRun{Text: text})
+	}
+
+	return runs
+}
+
+func (tv *TableView) richText(row, col int) []TextRun {
+	if tv.richTextProvider == nil || row < 0 || col < 0 {
+		return nil
+	}
+
+	return tv.richTextProvider.RichText(row, col)
+}
+
+// hideDefaultCellText makes the list view draw the text of the cell of nmlvcd
+// in its background color, so it can be drawn differently in the post paint
+// stage. It returns the color the text would have had.
+func (tv *TableView) hideDefaultCellText(nmlvcd *win.NMLVCUSTOMDRAW) Color {
+	if nmlvcd.Nmcd.UItemState&win.CDIS_SELECTED != 0 {
+		// The list view would paint the selection over the background
+		// color, so we take care of it.
+		nmlvcd.Nmcd.UItemState &^= win.CDIS_SELECTED
+
+		bg, text := tv.selectionColors()
+		nmlvcd.ClrTextBk = win.COLORREF(bg)
+		nmlvcd.ClrText = win.COLORREF(text)
+	}
+
+	if nmlvcd.ClrTextBk == win.CLR_DEFAULT {
+		nmlvcd.ClrTextBk = win.COLORREF(tv.itemBGColor)
+	}
+
+	text := Color(nmlvcd.ClrText)
+	nmlvcd.ClrText = nmlvcd.ClrTextBk
+
+	return text
+}
+
+// drawRichTextCell draws runs over the cell of nmlvcd, the default text of
+// which the list view has drawn invisibly.
+func (tv *TableView) drawRichTextCell(hwnd win.HWND, nmlvcd *win.NMLVCUSTOMDRAW, col int, runs []TextRun) {
+	rc := win.RECT{Top: nmlvcd.ISubItem, Left: win.LVIR_LABEL}
+	if 0 == win.SendMessage(hwnd, win.LVM_GETSUBITEMRECT, nmlvcd.Nmcd.DwItemSpec, uintptr(unsafe.Pointer(&rc))) {
+		return
+	}
+
+	bounds := rectangleFromRECT(rc)
+	bounds.X += 6
+	bounds.Width -= 12
+	bounds.Y++
+	bounds.Height -= 2
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return
+	}
+
+	canvas, err := newCanvasFromHDC(nmlvcd.Nmcd.Hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	fonts := make([]*Font, len(runs))
+	widths := make([]int, len(runs))
+	var totalWidth int
+
+	for i, run := range runs {
+		fonts[i] = tv.wrapTextFont
+		if run.Bold && !fonts[i].Bold() {
+			if font, err := NewFont(fonts[i].Family(), fonts[i].PointSize(), fonts[i].Style()|FontBold); err == nil {
+				fonts[i] = font
+			}
+		}
+
+		widths[i] = textWidth(nmlvcd.Nmcd.Hdc, fonts[i], canvas.dpiy, run.Text)
+		totalWidth += widths[i]
+	}
+
+	x := bounds.X
+	switch tv.columns.At(col).Alignment() {
+	case AlignCenter:
+		x += maxi(0, (bounds.Width-totalWidth)/2)
+
+	case AlignFar:
+		x += maxi(0, bounds.Width-totalWidth)
+	}
+
+	right := bounds.X + bounds.Width
+
+	for i, run := range runs {
+		if x >= right {
+			break
+		}
+
+		r := Rectangle{x, bounds.Y, mini(widths[i], right-x), bounds.Height}
+
+		if run.BackgroundColor != 0 {
+			if brush, err := NewSolidColorBrush(run.BackgroundColor); err == nil {
+				canvas.FillRectangle(brush, r)
+				brush.Dispose()
+			}
+		}
+
+		color := tv.wrapTextColor
+		if run.TextColor != 0 {
+			color = run.TextColor
+		}
+
+		canvas.DrawText(run.Text, fonts[i], color, r, TextVCenter|TextSingleLine|TextNoPrefix|TextEndEllipsis)
+
+		x += widths[i]
+	}
+}
+
+// textWidth returns the width of text in font, as displayed on hdc.
+func textWidth(hdc win.HDC, font *Font, dpi int, text string) int {
+	if text == "" {
+		return 0
+	}
+
+	hFontOld := win.SelectObject(hdc, win.HGDIOBJ(font.handleForDPI(dpi)))
+	defer win.SelectObject(hdc, hFontOld)
+
+	utf16 := syscall.StringToUTF16(text)
+
+	var size win.SIZE
+	if !win.GetTextExtentPoint32(hdc, &utf16[0], int32(len(utf16)-1), &size) {
+		return 0
+	}
+
+	return int(size.CX)
+}
@@ -0,0 +1,214 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"sort"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// RowsReorderable returns if the user can reorder rows using drag and drop.
+func (tv *TableView) RowsReorderable() bool {
+	return tv.rowsReorderable
+}
+
+// SetRowsReorderable sets if the user can reorder rows using drag and drop.
+//
+// Reordering requires the model to implement RowMover. If the dragged row is
+// selected, all selected rows are moved. Rows cannot be reordered while a row
+// filter is set.
+func (tv *TableView) SetRowsReorderable(reorderable bool) {
+	tv.rowsReorderable = reorderable
+
+	if !reorderable {
+		tv.endRowDrag(false)
+	}
+}
+
+// RowsReordered returns the event that is published after the user reordered
+// rows using drag and drop.
+func (tv *TableView) RowsReordered() *Event {
+	return tv.rowsReorderedPublisher.Event()
+}
+
+func (tv *TableView) beginRowDrag(hwnd win.HWND, index int) {
+	if !tv.rowsReorderable || tv.rowMover == nil || tv.rowFilter != nil || index < 0 {
+		return
+	}
+
+	tv.endCellEdit(true)
+
+	tv.rowDragHwnd = hwnd
+	tv.rowDragIndex = index
+	tv.rowDropIndex = -1
+
+	win.SetCapture(hwnd)
+}
+
+func (tv *TableView) cancelRowDragOnCaptureLoss() {
+	// Someone else took the capture, so we must not release it.
+	tv.rowDragHwnd = 0
+	tv.rowDropIndex = -1
+
+	tv.Invalidate()
+}
+
+func (tv *TableView) updateRowDrag() {
+	if dropIndex := tv.rowDropIndexAtCursor(); dropIndex != tv.rowDropIndex {
+		tv.rowDropIndex = dropIndex
+		tv.Invalidate()
+	}
+}
+
+func (tv *TableView) endRowDrag(drop bool) error {
+	hwnd := tv.rowDragHwnd
+	if hwnd == 0 {
+		return nil
+	}
+
+	// We reset this first, so we don't get here again when we lose the
+	// capture.
+	tv.rowDragHwnd = 0
+
+	dropIndex := tv.rowDropIndexAtCursor()
+	tv.rowDropIndex = -1
+
+	win.ReleaseCapture()
+
+	tv.Invalidate()
+
+	if !drop || dropIndex == -1 {
+		return nil
+	}
+
+	indexes := []int{tv.rowDragIndex}
+	if tv.IsRowSelected(tv.rowDragIndex) && tv.MultiSelection() {
+		indexes = tv.SelectedIndexes()
+	}
+
+	return tv.moveRows(indexes, dropIndex)
+}
+
+// rowDropIndexAtCursor returns the index of the row, before which dragged rows
+// would be inserted, if they were dropped at the current cursor position.
+func (tv *TableView) rowDropIndexAtCursor() int {
+	hwnd := tv.rowDragHwnd
+	if hwnd == 0 {
+		return -1
+	}
+
+	var pt win.POINT
+	if !win.GetCursorPos(&pt) || !win.ScreenToClient(hwnd, &pt) {
+		return -1
+	}
+
+	count := tv.RowCount()
+	if count == 0 {
+		return -1
+	}
+
+	first := tv.TopIndex()
+	last := mini(count-1, first+int(win.SendMessage(hwnd, win.LVM_GETCOUNTPERPAGE, 0, 0)))
+
+	for i := first; i <= last; i++ {
+		rc := win.RECT{Left: win.LVIR_BOUNDS}
+		if win.SendMessage(hwnd, win.LVM_GETITEMRECT, uintptr(i), uintptr(unsafe.Pointer(&rc))) == 0 {
+			return -1
+		}
+
+		if pt.Y < (rc.Top+rc.Bottom)/2 {
+			return i
+		}
+	}
+
+	if last == count-1 {
+		return count
+	}
+
+	return last
+}
+
+// moveRows moves the rows at indexes, so they are displayed before the row
+// that was at index to, and selects them.
+func (tv *TableView) moveRows(indexes []int, to int) error {
+	sort.Ints(indexes)
+
+	var above, below []int
+	for _, i := range indexes {
+		if i < to {
+			above = append(above, i)
+		} else {
+			below = append(below, i)
+		}
+	}
+
+	// Moving a row only shifts the rows between its old and new index, so
+	// rows above the drop position are moved bottom up and rows below it
+	// top down.
+	for i := len(above) - 1; i >= 0; i-- {
+		if from, dest := above[i], to-len(above)+i; from != dest {
+			if err := tv.rowMover.MoveRow(from, dest); err != nil {
+				return wrapError(err)
+			}
+		}
+	}
+	for i, from := range below {
+		if dest := to + i; from != dest {
+			if err := tv.rowMover.MoveRow(from, dest); err != nil {
+				return wrapError(err)
+			}
+		}
+	}
+
+	first := to - len(above)
+
+	if tv.MultiSelection() {
+		selected := make([]int, len(indexes))
+		for i := range selected {
+			selected[i] = first + i
+		}
+
+		if err := tv.SetSelectedIndexes(selected); err != nil {
+			return err
+		}
+	} else if err := tv.SetCurrentIndex(first); err != nil {
+		return err
+	}
+
+	tv.rowsReorderedPublisher.Publish()
+
+	return nil
+}
+
+func (tv *TableView) drawRowDropMarker(hdc win.HDC, row int, bounds win.RECT) {
+	var y int32
+	switch {
+	case row == tv.rowDropIndex:
+		y = bounds.Top
+
+	case row == tv.rowDropIndex-1 && tv.rowDropIndex == tv.RowCount():
+		y = bounds.Bottom - 2
+
+	default:
+		return
+	}
+
+	brush, err := NewSystemColorBrush(SysColorHighlight)
+	if err != nil {
+		return
+	}
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	canvas.FillRectangle(brush, Rectangle{int(bounds.Left), int(y), int(bounds.Right - bounds.Left), 2})
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// RowNumbersVisible returns if the *TableView displays a column of row
+// numbers.
+func (tv *TableView) RowNumbersVisible() bool {
+	return tv.rowNumbersVisible
+}
+
+// SetRowNumbersVisible sets if the *TableView displays a column of row
+// numbers.
+//
+// The row number column is displayed in front of all other columns and does
+// not scroll horizontally. It shows the 1-based position of each row in the
+// filtered and sorted view. It is not a model column, so it does not take
+// part in sorting and is not counted by column indices.
+func (tv *TableView) SetRowNumbersVisible(visible bool) (err error) {
+	if visible == tv.rowNumbersVisible {
+		return nil
+	}
+
+	checkBoxes := tv.CheckBoxes()
+
+	defer func() {
+		tv.hasFrozenColumn = tv.visibleFrozenColumnCount() > 0 || tv.rowNumbersVisible
+		tv.SetCheckBoxes(checkBoxes)
+		tv.applyImageList()
+
+		tv.updateRowNumberColumnWidth()
+		tv.updateLVSizes()
+		tv.updateHeaderImages()
+	}()
+
+	if visible {
+		var lvc win.LVCOLUMN
+		lvc.Mask = win.LVCF_FMT | win.LVCF_WIDTH | win.LVCF_TEXT | win.LVCF_SUBITEM
+		lvc.PszText = syscall.StringToUTF16Ptr("")
+
+		if -1 == int(win.SendMessage(tv.hwndFrozen, win.LVM_INSERTCOLUMN, 0, uintptr(unsafe.Pointer(&lvc)))) {
+			return newError("LVM_INSERTCOLUMN")
+		}
+	} else {
+		if win.FALSE == win.SendMessage(tv.hwndFrozen, win.LVM_DELETECOLUMN, 0, 0) {
+			return newError("LVM_DELETECOLUMN")
+		}
+	}
+
+	tv.rowNumbersVisible = visible
+
+	return nil
+}
+
+// frozenColumnOffset returns the number of list view columns in front of the
+// frozen model columns.
+func (tv *TableView) frozenColumnOffset() int32 {
+	if tv.rowNumbersVisible {
+		return 1
+	}
+
+	return 0
+}
+
+func (tv *TableView) isRowNumberColumn(hwnd win.HWND, lvColIdx int32) bool {
+	return tv.rowNumbersVisible && hwnd == tv.hwndFrozen && lvColIdx == 0
+}
+
+func (tv *TableView) rowNumberColumnWidth() int {
+	if !tv.rowNumbersVisible {
+		return 0
+	}
+
+	return int(win.SendMessage(tv.hwndFrozen, win.LVM_GETCOLUMNWIDTH, 0, 0))
+}
+
+// updateRowNumberColumnWidth makes the row number column wide enough for the
+// largest row number.
+func (tv *TableView) updateRowNumberColumnWidth() {
+	if !tv.rowNumbersVisible {
+		return
+	}
+
+	digits := len(strconv.Itoa(maxi(tv.itemCount(), 1)))
+
+	width := tv.calculateTextSizeImpl(strings.Repeat("9", digits)).Width + tableViewCellPadding
+
+	// The row number column hosts item images and check boxes.
+	iconWidth := int(win.GetSystemMetrics(win.SM_CXSMICON))
+	if tv.displaysImages(tv.hwndFrozen) {
+		width += iconWidth
+	}
+	if tv.CheckBoxes() {
+		width += iconWidth
+	}
+
+	if width == tv.rowNumberColumnWidth() {
+		return
+	}
+
+	win.SendMessage(tv.hwndFrozen, win.LVM_SETCOLUMNWIDTH, 0, uintptr(width))
+
+	tv.updateLVSizes()
+}
+
+// prepareRowNumberCell sets up the colors of a row number cell for the default
+// drawing of the list view.
+func (tv *TableView) prepareRowNumberCell(nmlvcd *win.NMLVCUSTOMDRAW) {
+	nmlvcd.ClrTextBk = win.COLORREF(win.GetSysColor(win.COLOR_BTNFACE))
+	nmlvcd.ClrText = win.COLORREF(win.GetSysColor(win.COLOR_GRAYTEXT))
+}
@@ -0,0 +1,175 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// SummaryRowProvider returns the SummaryRowProvider of the *TableView, or nil
+// if no summary row is displayed.
+func (tv *TableView) SummaryRowProvider() SummaryRowProvider {
+	return tv.summaryRowProvider
+}
+
+// SetSummaryRowProvider sets the SummaryRowProvider, that provides the values
+// of a summary row displayed below the data rows.
+//
+// The summary row does not scroll vertically and is repainted when the model
+// changes. Pass nil to remove the summary row.
+func (tv *TableView) SetSummaryRowProvider(provider SummaryRowProvider) {
+	tv.summaryRowProvider = provider
+
+	tv.updateLVSizes()
+	tv.WidgetBase.Invalidate()
+}
+
+func (tv *TableView) summaryRowHeight() int {
+	if tv.summaryRowProvider == nil {
+		return 0
+	}
+
+	return tv.fixedRowHeight()
+}
+
+// fixedRowHeight returns the height of rows that the *TableView draws itself,
+// like the summary row and pinned rows.
+func (tv *TableView) fixedRowHeight() int {
+	return maxi(tv.effectiveRowHeight, tv.calculateTextSizeImpl("gM").Height+tv.rowPadding()+2)
+}
+
+func (tv *TableView) summaryRowBounds() win.RECT {
+	var rc win.RECT
+	win.GetClientRect(tv.hWnd, &rc)
+
+	rc.Top = rc.Bottom - int32(tv.summaryRowHeight())
+
+	return rc
+}
+
+func (tv *TableView) invalidateSummaryRow() {
+	if tv.summaryRowProvider == nil {
+		return
+	}
+
+	rc := tv.summaryRowBounds()
+	win.InvalidateRect(tv.hWnd, &rc, false)
+}
+
+// listViewBoundsInClient returns the client area of the list view hwnd, in
+// client coordinates of the *TableView.
+func (tv *TableView) listViewBoundsInClient(hwnd win.HWND) win.RECT {
+	var rc win.RECT
+	win.GetClientRect(hwnd, &rc)
+
+	return tv.rectToClient(hwnd, rc)
+}
+
+// rectToClient converts rc from client coordinates of hwnd to client
+// coordinates of the *TableView.
+func (tv *TableView) rectToClient(hwnd win.HWND, rc win.RECT) win.RECT {
+	pts := [2]win.POINT{{rc.Left, rc.Top}, {rc.Right, rc.Bottom}}
+
+	for i := range pts {
+		win.ClientToScreen(hwnd, &pts[i])
+		win.ScreenToClient(tv.hWnd, &pts[i])
+	}
+
+	// Mirrored windows may swap left and right.
+	if pts[0].X > pts[1].X {
+		pts[0].X, pts[1].X = pts[1].X, pts[0].X
+	}
+
+	return win.RECT{pts[0].X, pts[0].Y, pts[1].X, pts[1].Y}
+}
+
+func (tv *TableView) drawSummaryRow(hdc win.HDC) {
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	rc := tv.summaryRowBounds()
+	bounds := rectangleFromRECT(rc)
+
+	if brush, err := NewSystemColorBrush(SysColorBtnFace); err == nil {
+		canvas.FillRectangle(brush, bounds)
+	}
+	if brush, err := NewSystemColorBrush(SysColorBtnShadow); err == nil {
+		canvas.FillRectangle(brush, Rectangle{bounds.X, bounds.Y, bounds.Width, 1})
+	}
+
+	font := tv.Font()
+	color := Color(win.GetSysColor(win.COLOR_BTNTEXT))
+
+	for _, tvc := range tv.columns.items {
+		cell, ok := tv.fixedRowCellBounds(tvc, rc.Top+1, rc.Bottom)
+		if !ok {
+			continue
+		}
+
+		col := tv.columns.Index(tvc)
+
+		text := tv.formatValue(tv.summaryRowProvider.SummaryValue(col), col)
+		if text == "" {
+			continue
+		}
+
+		canvas.DrawText(text, font, color, cell, tvc.fixedRowTextFormat())
+	}
+}
+
+// fixedRowCellBounds returns the text bounds of the cell of column tvc in a
+// row between top and bottom, that is displayed outside of the list views.
+// The cell is aligned with the header item of the column.
+func (tv *TableView) fixedRowCellBounds(tvc *TableViewColumn, top, bottom int32) (Rectangle, bool) {
+	if !tvc.visible {
+		return Rectangle{}, false
+	}
+
+	hwnd := tv.hwndNormal
+	if tvc.frozen {
+		hwnd = tv.hwndFrozen
+	}
+
+	headerHwnd := win.HWND(win.SendMessage(hwnd, win.LVM_GETHEADER, 0, 0))
+
+	var rcItem win.RECT
+	if 0 == win.SendMessage(headerHwnd, win.HDM_GETITEMRECT, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(&rcItem))) {
+		return Rectangle{}, false
+	}
+
+	rcItem = tv.rectToClient(headerHwnd, rcItem)
+
+	// Cells must not spill into the other list view or the scroll bar.
+	rcSection := tv.listViewBoundsInClient(hwnd)
+	rcItem.Left = int32(maxi(int(rcItem.Left), int(rcSection.Left)))
+	rcItem.Right = int32(mini(int(rcItem.Right), int(rcSection.Right)))
+	rcItem.Top, rcItem.Bottom = top, bottom
+
+	cell := rectangleFromRECT(rcItem)
+	cell.X += 6
+	cell.Width -= 12
+
+	return cell, cell.Width > 0
+}
+
+func (tvc *TableViewColumn) fixedRowTextFormat() DrawTextFormat {
+	format := TextVCenter | TextSingleLine | TextEndEllipsis | TextNoPrefix
+	switch tvc.alignment {
+	case AlignCenter:
+		format |= TextCenter
+
+	case AlignFar:
+		format |= TextRight
+	}
+
+	return format
+}
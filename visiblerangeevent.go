@@ -0,0 +1,45 @@
+// Copyright 2017 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package walk
+
+type VisibleRangeEventHandler func(top, count int)
+
+type VisibleRangeEvent struct {
+	handlers []VisibleRangeEventHandler
+}
+
+func (e *VisibleRangeEvent) Attach(handler VisibleRangeEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *VisibleRangeEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type VisibleRangeEventPublisher struct {
+	event VisibleRangeEvent
+}
+
+func (p *VisibleRangeEventPublisher) Event() *VisibleRangeEvent {
+	return &p.event
+}
+
+func (p *VisibleRangeEventPublisher) Publish(top, count int) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(top, count)
+		}
+	}
+}